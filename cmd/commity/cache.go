@@ -0,0 +1,791 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/michaelrampl/commity/internal/config"
+	"github.com/michaelrampl/commity/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// repoDataDirs are the subdirectories of the data directory holding repository-specific files.
+var repoDataDirs = []string{"cache", "suggestions", "history", "drafts", "roots"}
+
+// cacheLockTimeout is how long a run waits for a concurrent run to finish writing the cache.
+const cacheLockTimeout = 10 * time.Second
+
+// globalCacheName is the name of the cache file within the cache directory holding the values stored globally.
+const globalCacheName = "global.yaml"
+
+// CacheFile is the content of a repository-specific cache file.
+// The values are partitioned by the scope of their entry, values of the repository scope are kept on the top level.
+type CacheFile struct {
+	Repository string                 `yaml:"repository"`          // The path of the repository the values belong to
+	Identity   string                 `yaml:"identity,omitempty"`  // The identity the file is kept under, if not the repository path
+	Values     CacheValues            `yaml:"values"`              // The values stored for the repository
+	Branches   map[string]CacheValues `yaml:"branches,omitempty"`  // The values stored per branch
+	Worktrees  map[string]CacheValues `yaml:"worktrees,omitempty"` // The values stored per worktree path
+}
+
+// CacheValues maps entry names to their stored values.
+type CacheValues map[string]CachedValue
+
+// CachedValue is a stored value together with the time it was stored.
+type CachedValue struct {
+	Value  string    `yaml:"value"`            // The value of the entry
+	Stored time.Time `yaml:"stored,omitempty"` // When the value was stored, zero for values written by older versions
+}
+
+// UnmarshalYAML decodes a cached value. Older versions of commity stored the plain value without a timestamp.
+func (v *CachedValue) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = CachedValue{Value: node.Value}
+		return nil
+	}
+	type raw CachedValue
+	return node.Decode((*raw)(v))
+}
+
+// expired reports whether the value is older than the given time to live at the given time.
+// Values without a timestamp are considered expired, as their age is unknown.
+func (v CachedValue) expired(ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && (v.Stored.IsZero() || now.Sub(v.Stored) > ttl)
+}
+
+// UnmarshalYAML decodes a cache file. Older versions of commity stored the values as a flat map
+// without the repository path, such files are read with an empty repository.
+func (c *CacheFile) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "values" && node.Content[i+1].Kind == yaml.MappingNode {
+				type raw CacheFile
+				return node.Decode((*raw)(c))
+			}
+		}
+	}
+	c.Repository = ""
+	return node.Decode(&c.Values)
+}
+
+// partition returns the values stored in the file for the scope at the given location, or nil if there are none.
+// With create set, a missing partition is added to the file. The global cache file keeps its values on the top level.
+func (c *CacheFile) partition(scope config.StoreScope, location cacheLocation, create bool) CacheValues {
+	var partitions *map[string]CacheValues
+	var key string
+	switch scope {
+	case config.ScopeBranch:
+		if location.branch == "" {
+			// A detached HEAD has no branch to store the values for
+			return nil
+		}
+		partitions, key = &c.Branches, location.branch
+	case config.ScopeWorktree:
+		partitions, key = &c.Worktrees, location.worktree
+	default:
+		if c.Values == nil && create {
+			c.Values = CacheValues{}
+		}
+		return c.Values
+	}
+	if (*partitions)[key] == nil && create {
+		if *partitions == nil {
+			*partitions = make(map[string]CacheValues)
+		}
+		(*partitions)[key] = CacheValues{}
+	}
+	return (*partitions)[key]
+}
+
+// count returns the number of values stored in the file.
+func (c *CacheFile) count() int {
+	count := len(c.Values)
+	for _, values := range c.Branches {
+		count += len(values)
+	}
+	for _, values := range c.Worktrees {
+		count += len(values)
+	}
+	return count
+}
+
+// cacheLocation identifies the partitions of the cache a run reads and writes.
+type cacheLocation struct {
+	identity   string // The key the cache file of the repository is kept under (see cacheIdentity)
+	repository string // The main worktree of the repository
+	worktree   string // The worktree commity runs in
+	branch     string // The checked out branch, empty for a detached HEAD
+}
+
+// newCacheLocation returns the cache location of a run in the given worktree.
+// All worktrees of a repository share one cache file, kept under the identity of the main worktree.
+// A cache file kept under the worktree or repository path by older versions is moved to it.
+func newCacheLocation(repoPath string, branch string, strategy config.CacheIdentity) cacheLocation {
+	location := resolveCacheLocation(repoPath, branch, strategy)
+	if err := migrateCacheFile(location); err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to migrate cache: %v", err)))
+	}
+	return location
+}
+
+// resolveCacheLocation returns the cache location of a run in the given worktree like newCacheLocation,
+// without migrating any files, e.g. to inspect the cache.
+func resolveCacheLocation(repoPath string, branch string, strategy config.CacheIdentity) cacheLocation {
+	repository, err := utils.GetMainWorktree(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to locate the main worktree: %v", err)))
+		repository = repoPath
+	}
+	identity, err := cacheIdentity(repository, strategy.OrDefault())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: %v, the repository path is used to identify the cache", err)))
+		identity = repository
+	}
+
+	return cacheLocation{identity: identity, repository: repository, worktree: repoPath, branch: branch}
+}
+
+// cacheIdentity returns the key the cache file of the repository is kept under for the given strategy.
+// The path strategy uses the path itself, so the files of older versions keep their names.
+func cacheIdentity(repository string, strategy config.CacheIdentity) (string, error) {
+	switch strategy {
+	case config.IdentityRemote:
+		remote, err := utils.GetRemoteIdentity(repository)
+		if err != nil {
+			return "", err
+		}
+		return "remote:" + remote, nil
+	case config.IdentityRoot:
+		root, err := rootCommit(repository)
+		if err != nil {
+			return "", err
+		}
+		return "root:" + root, nil
+	default:
+		return repository, nil
+	}
+}
+
+// rootCommit returns the hash of the root commit of the repository. Finding it walks the whole history,
+// so the hash is remembered in the data directory and only looked up again once it is no longer a root commit
+// of the repository, e.g. after the repository was replaced.
+func rootCommit(repository string) (string, error) {
+	rootFile, err := getRepoDataFilePath("roots", repository)
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile(rootFile); err == nil {
+		var root string
+		if yaml.Unmarshal(data, &root) == nil && utils.IsRootCommit(repository, root) {
+			return root, nil
+		}
+	}
+
+	root, err := utils.GetRootCommit(repository)
+	if err != nil {
+		return "", err
+	}
+	if err := writeRepoDataFile("roots", repository, root); err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to remember the root commit: %v", err)))
+	}
+	return root, nil
+}
+
+// migrateCacheFile moves the cache file older versions kept under the path of the worktree or repository
+// to the identity of the location. Nothing is done if the location has a file already.
+func migrateCacheFile(location cacheLocation) error {
+	target, err := getCacheFilePath(location.identity)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if len(legacyCacheFiles(location)) == 0 {
+		return nil
+	}
+
+	unlock, err := lockCache()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if _, err := os.Stat(target); !errors.Is(err, os.ErrNotExist) {
+		// Migrated by a concurrent run in the meantime
+		return nil
+	}
+
+	sources := legacyCacheFiles(location)
+	if len(sources) == 0 {
+		return nil
+	}
+	cache, err := readCacheFile(sources[0])
+	if err != nil {
+		return err
+	}
+	cache.Repository = location.repository
+	cache.Identity = cacheFileIdentity(location)
+	if err := writeCacheFile(target, cache); err != nil {
+		return err
+	}
+	return os.Remove(sources[0])
+}
+
+// legacyCacheFiles returns the existing cache files older versions kept under the path of the worktree
+// or repository of the location, other than the cache file of the location itself.
+func legacyCacheFiles(location cacheLocation) []string {
+	var files []string
+	for _, path := range []string{location.worktree, location.repository} {
+		if path == location.identity {
+			continue
+		}
+		file, err := getCacheFilePath(path)
+		if err != nil || contains(files, file) {
+			continue
+		}
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// cacheFileIdentity returns the identity recorded in the cache file of the location,
+// which is empty if the file is kept under the repository path.
+func cacheFileIdentity(location cacheLocation) string {
+	if location.identity == location.repository {
+		return ""
+	}
+	return location.identity
+}
+
+// storeFallbacks lists the scopes looked up in order for the stored value of an entry with the given scope.
+// Broader scopes provide the value until one is stored in the scope of the entry, e.g. after its scope was changed.
+var storeFallbacks = map[config.StoreScope][]config.StoreScope{
+	config.ScopeRepository: {config.ScopeRepository, config.ScopeGlobal},
+	config.ScopeBranch:     {config.ScopeBranch, config.ScopeRepository, config.ScopeGlobal},
+	config.ScopeWorktree:   {config.ScopeWorktree, config.ScopeRepository, config.ScopeGlobal},
+	config.ScopeGlobal:     {config.ScopeGlobal},
+}
+
+// getCacheFilePath returns the repository-specific cache file path for the identity of a repository.
+// It places the file inside a "cache" subfolder within the data directory.
+func getCacheFilePath(identity string) (string, error) {
+	return getRepoDataFilePath("cache", identity)
+}
+
+// getGlobalCacheFilePath returns the path of the cache file holding the values stored globally.
+func getGlobalCacheFilePath() (string, error) {
+	dataDir, err := utils.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "cache", globalCacheName), nil
+}
+
+// readCacheFile reads a cache file, returning an empty cache if the file doesn't exist.
+func readCacheFile(cacheFile string) (*CacheFile, error) {
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// No persisted file yet; return an empty cache.
+			return &CacheFile{Values: CacheValues{}}, nil
+		}
+		return nil, err
+	}
+	var cache CacheFile
+	if err := yaml.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	if cache.Values == nil {
+		cache.Values = CacheValues{}
+	}
+	return &cache, nil
+}
+
+// writeCacheFile persists the cache as YAML, creating the cache directory if it doesn't exist.
+// The file is replaced atomically, callers changing a file they read hold the cache lock (see lockCache).
+func writeCacheFile(cacheFile string, cache *CacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(cacheFile, data, 0644)
+}
+
+// lockCache acquires the advisory lock serializing changes of the cache files between concurrent runs,
+// e.g. in several worktrees of a repository. It returns a function releasing the lock.
+func lockCache() (func(), error) {
+	dataDir, err := utils.GetDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return utils.LockFile(filepath.Join(dataDir, "cache", ".lock"), cacheLockTimeout)
+}
+
+// loadCaches reads the cache file kept under the identity of a repository and the global cache file.
+func loadCaches(identity string) (*CacheFile, *CacheFile, error) {
+	cacheFile, err := getCacheFilePath(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache, err := readCacheFile(cacheFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	globalFile, err := getGlobalCacheFilePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	global, err := readCacheFile(globalFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache, global, nil
+}
+
+// runCache implements the cache command and dispatches its subcommands.
+func runCache(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: commity cache list|show|clear|prune [options]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		runCacheList(args[1:])
+	case "show":
+		runCacheShow(args[1:])
+	case "clear":
+		runCacheClear(args[1:])
+	case "prune":
+		runCachePrune(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Unknown cache command: %s", args[0])))
+		os.Exit(1)
+	}
+}
+
+// cacheDirectory returns the directory holding the cache files.
+func cacheDirectory() string {
+	dataDir, err := utils.GetDataDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error locating data directory: %v", err)))
+		os.Exit(1)
+	}
+	return filepath.Join(dataDir, "cache")
+}
+
+// cacheFiles returns the paths of all repository-specific cache files, sorted by name.
+func cacheFiles() []string {
+	files, err := filepath.Glob(filepath.Join(cacheDirectory(), "*.yaml"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error listing cache files: %v", err)))
+		os.Exit(1)
+	}
+	var repoFiles []string
+	for _, file := range files {
+		if filepath.Base(file) != globalCacheName {
+			repoFiles = append(repoFiles, file)
+		}
+	}
+	sort.Strings(repoFiles)
+	return repoFiles
+}
+
+// repositoryExists reports whether the repository at the given path still exists.
+// Worktrees of bare repositories are kept for the Git directory, which has no .git subdirectory.
+func repositoryExists(repoPath string) bool {
+	if _, err := os.Stat(filepath.Join(repoPath, ".git")); err == nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(repoPath, "HEAD"))
+	return err == nil
+}
+
+// cacheRepositoryLocation returns the cache location of the given directory's repository,
+// using the identity strategy of its configuration. It exits if the directory is not within a repository.
+// No files are migrated, so inspecting the cache leaves it unchanged.
+func cacheRepositoryLocation(directory string) cacheLocation {
+	repoPath, err := utils.FindGitRepository(resolveDirectory(directory))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error findig git repository: %v", err)))
+		os.Exit(1)
+	}
+	var strategy config.CacheIdentity
+	if cfg, _, err := utils.LoadConfig(repoPath); err == nil {
+		strategy = cfg.Cache.Identity
+	}
+	return resolveCacheLocation(repoPath, "", strategy)
+}
+
+// cacheCommandFile returns the cache file a cache subcommand works on: the global cache file,
+// or the cache file of the repository in the given directory together with its location.
+func cacheCommandFile(directory string, global bool) (string, string, cacheLocation) {
+	var cacheFile, name string
+	var location cacheLocation
+	var err error
+	if global {
+		cacheFile, err = getGlobalCacheFilePath()
+		name = "the global cache"
+	} else {
+		location = cacheRepositoryLocation(directory)
+		cacheFile, err = getCacheFilePath(location.identity)
+		name = "the cache of " + location.repository
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error locating cache: %v", err)))
+		os.Exit(1)
+	}
+	return cacheFile, name, location
+}
+
+// lockCacheOrExit acquires the cache lock for a cache subcommand, exiting if it cannot be acquired.
+func lockCacheOrExit() func() {
+	unlock, err := lockCache()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error locking cache: %v", err)))
+		os.Exit(1)
+	}
+	return unlock
+}
+
+// runCacheList prints the repositories that have a cache file.
+func runCacheList(args []string) {
+	flags := flag.NewFlagSet("cache list", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity cache list")
+	}
+	flags.Parse(args)
+
+	listed := 0
+	if globalFile, err := getGlobalCacheFilePath(); err == nil {
+		if global, err := readCacheFile(globalFile); err == nil && global.count() > 0 {
+			fmt.Printf("global: %d value(s)\n", global.count())
+			listed++
+		}
+	}
+	for _, file := range cacheFiles() {
+		cache, err := readCacheFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to read %s: %v", file, err)))
+			continue
+		}
+		repository := cache.Repository
+		switch {
+		case repository == "":
+			repository = fmt.Sprintf("%s (unknown repository, written by an older version)", filepath.Base(file))
+		case !repositoryExists(repository):
+			repository += style_warning.Render(" (missing)")
+		}
+		if cache.Identity != "" {
+			repository += fmt.Sprintf(" [%s]", cache.Identity)
+		}
+		fmt.Printf("%s: %d value(s)\n", repository, cache.count())
+		listed++
+	}
+	if listed == 0 {
+		fmt.Println("No cached values")
+	}
+}
+
+// printCacheValues prints the values of a cache partition with the dates they were stored, sorted by key.
+func printCacheValues(title string, values CacheValues) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Println(title)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := values[key]
+		stored := "unknown"
+		if !value.Stored.IsZero() {
+			stored = value.Stored.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("  %s: %s (stored %s)\n", key, strings.ReplaceAll(value.Value, "\n", "\\n"), stored)
+	}
+}
+
+// sortedKeys returns the keys of the partitions, sorted.
+func sortedKeys(partitions map[string]CacheValues) []string {
+	keys := make([]string, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runCacheShow prints the cached values of a repository, or the global values.
+func runCacheShow(args []string) {
+	flags := flag.NewFlagSet("cache show", flag.ExitOnError)
+	directory := flags.String("directory", "", "The directory of the repository")
+	global := flags.Bool("global", false, "Show the values stored globally")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity cache show [options]")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cacheFile, name, location := cacheCommandFile(*directory, *global)
+	if _, err := os.Stat(cacheFile); !*global && errors.Is(err, os.ErrNotExist) {
+		// Show the values the next run takes over from older versions, without migrating them now
+		if sources := legacyCacheFiles(location); len(sources) > 0 {
+			cacheFile = sources[0]
+		}
+	}
+	cache, err := readCacheFile(cacheFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading cache: %v", err)))
+		os.Exit(1)
+	}
+	if cache.count() == 0 {
+		fmt.Printf("No values in %s\n", name)
+		return
+	}
+	if *global {
+		printCacheValues("Global:", cache.Values)
+		return
+	}
+	printCacheValues("Repository:", cache.Values)
+	for _, branch := range sortedKeys(cache.Branches) {
+		printCacheValues(fmt.Sprintf("Branch %s:", branch), cache.Branches[branch])
+	}
+	for _, worktree := range sortedKeys(cache.Worktrees) {
+		printCacheValues(fmt.Sprintf("Worktree %s:", worktree), cache.Worktrees[worktree])
+	}
+}
+
+// runCacheClear removes the cached values of a repository, or only the given keys.
+// Keys are removed from all partitions of the cache file. The cache files kept under the worktree or repository
+// path by older versions are cleared as well, so the next run doesn't take them over.
+func runCacheClear(args []string) {
+	flags := flag.NewFlagSet("cache clear", flag.ExitOnError)
+	directory := flags.String("directory", "", "The directory of the repository")
+	global := flags.Bool("global", false, "Clear the values stored globally")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity cache clear [options] [key...]")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cacheFile, name, location := cacheCommandFile(*directory, *global)
+	unlock := lockCacheOrExit()
+	defer unlock()
+
+	files := []string{cacheFile}
+	if !*global {
+		files = append(files, legacyCacheFiles(location)...)
+	}
+
+	if flags.NArg() == 0 {
+		for _, file := range files {
+			if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error clearing cache: %v", err)))
+				os.Exit(1)
+			}
+		}
+		fmt.Println(style_success.Render(fmt.Sprintf("Cleared %s", name)))
+		return
+	}
+
+	found := make(map[string]bool)
+	for _, file := range files {
+		cache, err := readCacheFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading cache: %v", err)))
+			os.Exit(1)
+		}
+		partitions := []CacheValues{cache.Values}
+		for _, values := range cache.Branches {
+			partitions = append(partitions, values)
+		}
+		for _, values := range cache.Worktrees {
+			partitions = append(partitions, values)
+		}
+		changed := false
+		for _, key := range flags.Args() {
+			for _, values := range partitions {
+				if _, ok := values[key]; ok {
+					delete(values, key)
+					found[key] = true
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+		removeEmptyPartitions(cache)
+		if err := writeCacheFile(file, cache); err != nil {
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error writing cache: %v", err)))
+			os.Exit(1)
+		}
+	}
+
+	var cleared []string
+	for _, key := range flags.Args() {
+		if !found[key] {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: %s is not cached", key)))
+			continue
+		}
+		cleared = append(cleared, key)
+	}
+	if len(cleared) == 0 {
+		return
+	}
+	fmt.Println(style_success.Render(fmt.Sprintf("Cleared %s from %s", strings.Join(cleared, ", "), name)))
+}
+
+// removeEmptyPartitions removes the branches and worktrees without values from the cache file.
+func removeEmptyPartitions(cache *CacheFile) {
+	for branch, values := range cache.Branches {
+		if len(values) == 0 {
+			delete(cache.Branches, branch)
+		}
+	}
+	for worktree, values := range cache.Worktrees {
+		if len(values) == 0 {
+			delete(cache.Worktrees, worktree)
+		}
+	}
+}
+
+// repoDataExists reports whether any data subdirectory holds a repository-specific file of the given path.
+func repoDataExists(repoPath string) bool {
+	for _, subDir := range repoDataDirs {
+		dataFile, err := getRepoDataFilePath(subDir, repoPath)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(dataFile); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// removeRepoData removes the repository-specific files of the given path from all data subdirectories.
+func removeRepoData(repoPath string) {
+	for _, subDir := range repoDataDirs {
+		dataFile, err := getRepoDataFilePath(subDir, repoPath)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(dataFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to remove %s: %v", dataFile, err)))
+		}
+	}
+}
+
+// runCachePrune removes the cache files of repositories that no longer exist, together with their
+// suggestions, history and drafts. Cache files shared by clones are kept. For the remaining repositories,
+// the values of deleted branches and removed worktrees are dropped.
+func runCachePrune(args []string) {
+	flags := flag.NewFlagSet("cache prune", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "Only print what would be pruned")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity cache prune [options]")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	unlock := lockCacheOrExit()
+	defer unlock()
+
+	action := "Pruned"
+	if *dryRun {
+		action = "Would prune"
+	}
+	pruned := 0
+	for _, file := range cacheFiles() {
+		cache, err := readCacheFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to read %s: %v", file, err)))
+			continue
+		}
+		// Files of older versions don't know their repository, so they are kept.
+		if cache.Repository == "" {
+			continue
+		}
+
+		exists := repositoryExists(cache.Repository)
+		if !exists && cache.Identity == "" {
+			pruned++
+			fmt.Printf("%s %s\n", action, cache.Repository)
+			if !*dryRun {
+				if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+					fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to remove %s: %v", file, err)))
+				}
+				removeRepoData(cache.Repository)
+				for worktree := range cache.Worktrees {
+					removeRepoData(worktree)
+				}
+			}
+			continue
+		}
+
+		changed := false
+		if !exists {
+			// Files kept under the remote or root identity are shared with other clones, the repository is only
+			// the clone that wrote the file last. Only the data of that clone is removed.
+			if repoDataExists(cache.Repository) {
+				fmt.Printf("%s %s, kept the cache of %s shared with other clones\n", action, cache.Repository, cache.Identity)
+				pruned++
+				if !*dryRun {
+					removeRepoData(cache.Repository)
+				}
+			}
+		} else if len(cache.Branches) > 0 {
+			branches, err := utils.GetBranches(cache.Repository)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to read branches of %s: %v", cache.Repository, err)))
+			} else {
+				for _, branch := range sortedKeys(cache.Branches) {
+					if !contains(branches, branch) {
+						fmt.Printf("%s branch %s of %s\n", action, branch, cache.Repository)
+						delete(cache.Branches, branch)
+						changed = true
+					}
+				}
+			}
+		}
+		for _, worktree := range sortedKeys(cache.Worktrees) {
+			if !repositoryExists(worktree) {
+				fmt.Printf("%s worktree %s of %s\n", action, worktree, cache.Repository)
+				delete(cache.Worktrees, worktree)
+				if !*dryRun {
+					removeRepoData(worktree)
+				}
+				changed = true
+			}
+		}
+		if changed {
+			pruned++
+			if !*dryRun {
+				if err := writeCacheFile(file, cache); err != nil {
+					fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to write %s: %v", file, err)))
+				}
+			}
+		}
+	}
+	if pruned == 0 {
+		fmt.Println("Nothing to prune")
+	}
+}
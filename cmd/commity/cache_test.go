@@ -0,0 +1,263 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+func TestReadCacheFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    CacheFile
+	}{
+		{
+			name:    "current format",
+			content: "repository: /src/app\nvalues:\n  type:\n    value: feat\n    stored: 2024-05-01T12:00:00Z\n",
+			want:    CacheFile{Repository: "/src/app", Values: CacheValues{"type": {Value: "feat", Stored: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}}},
+		},
+		{
+			name:    "values without timestamp",
+			content: "repository: /src/app\nvalues:\n  type: feat\n",
+			want:    CacheFile{Repository: "/src/app", Values: CacheValues{"type": {Value: "feat"}}},
+		},
+		{
+			name:    "older flat format",
+			content: "type: feat\nscope: api\n",
+			want:    CacheFile{Values: CacheValues{"type": {Value: "feat"}, "scope": {Value: "api"}}},
+		},
+		{
+			name:    "older format with an entry named values",
+			content: "values: some\n",
+			want:    CacheFile{Values: CacheValues{"values": {Value: "some"}}},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    CacheFile{Values: CacheValues{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "cache.yaml")
+			if err := os.WriteFile(file, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cache, err := readCacheFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*cache, test.want) {
+				t.Errorf("readCacheFile() = %+v, want %+v", *cache, test.want)
+			}
+		})
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		pruned bool
+	}{
+		{name: "prune", pruned: true},
+		{name: "dry run", args: []string{"-dry-run"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useDataDir(t)
+			existing := initRepo(t)
+			missing := filepath.Join(t.TempDir(), "removed")
+
+			for _, repoPath := range []string{existing, missing} {
+				cacheFile, err := getCacheFilePath(repoPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := writeCacheFile(cacheFile, &CacheFile{Repository: repoPath, Values: CacheValues{"type": {Value: "feat"}}}); err != nil {
+					t.Fatal(err)
+				}
+				if err := writeRepoDataFile("history", repoPath, []HistoryRecord{{Message: "feat: add login"}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			legacy := filepath.Join(cacheDirectory(), "0123456789abcdef0123456789abcdef.yaml")
+			if err := os.WriteFile(legacy, []byte("type: fix\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			runCachePrune(test.args)
+
+			for _, subDir := range []string{"cache", "history"} {
+				file, err := getRepoDataFilePath(subDir, missing)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := os.Stat(file); os.IsNotExist(err) != test.pruned {
+					t.Errorf("%s file of the missing repository removed = %v, want %v", subDir, os.IsNotExist(err), test.pruned)
+				}
+				if file, err = getRepoDataFilePath(subDir, existing); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := os.Stat(file); err != nil {
+					t.Errorf("%s file of the existing repository: %v", subDir, err)
+				}
+			}
+			if _, err := os.Stat(legacy); err != nil {
+				t.Errorf("cache file of an older version: %v", err)
+			}
+		})
+	}
+}
+
+func TestCachedValueExpired(t *testing.T) {
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		stored time.Time
+		ttl    time.Duration
+		want   bool
+	}{
+		{name: "no expiry", stored: now.Add(-365 * 24 * time.Hour)},
+		{name: "no expiry without timestamp"},
+		{name: "within ttl", stored: now.Add(-6 * 24 * time.Hour), ttl: 7 * 24 * time.Hour},
+		{name: "exactly ttl", stored: now.Add(-7 * 24 * time.Hour), ttl: 7 * 24 * time.Hour},
+		{name: "older than ttl", stored: now.Add(-8 * 24 * time.Hour), ttl: 7 * 24 * time.Hour, want: true},
+		{name: "unknown age", ttl: 7 * 24 * time.Hour, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value := CachedValue{Value: "feat", Stored: test.stored}
+			if got := value.expired(test.ttl, now); got != test.want {
+				t.Errorf("expired() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRestoreParamMapTTL(t *testing.T) {
+	useDataDir(t)
+	repoPath := t.TempDir()
+	now := time.Now()
+
+	cacheFile, err := getCacheFilePath(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &CacheFile{Repository: repoPath, Values: CacheValues{
+		"fresh":  {Value: "a", Stored: now.Add(-time.Hour)},
+		"stale":  {Value: "b", Stored: now.Add(-48 * time.Hour)},
+		"legacy": {Value: "c"},
+		"kept":   {Value: "d", Stored: now.Add(-48 * time.Hour)},
+	}}
+	if err := writeCacheFile(cacheFile, cache); err != nil {
+		t.Fatal(err)
+	}
+
+	day := 24 * time.Hour
+	paramMap := ParamMap{}
+	storedKeys := map[string]storedEntry{
+		"fresh":  {config.ScopeRepository, day},
+		"stale":  {config.ScopeRepository, day},
+		"legacy": {config.ScopeRepository, day},
+		"kept":   {config.ScopeRepository, 0},
+	}
+	restoreParamMap(&paramMap, storedKeys, cacheLocation{identity: repoPath, repository: repoPath, worktree: repoPath, branch: "main"})
+
+	if want := (ParamMap{"fresh": "a", "kept": "d"}); !reflect.DeepEqual(paramMap, want) {
+		t.Errorf("restoreParamMap() = %v, want %v", paramMap, want)
+	}
+}
+
+func TestNewCacheLocationMigratesCacheFile(t *testing.T) {
+	useDataDir(t)
+	repoPath := initRepo(t, "feat: add login")
+
+	// Older versions kept the cache file under the repository path
+	legacy, err := getCacheFilePath(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("type: feat\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	location := newCacheLocation(repoPath, "main", config.IdentityRoot)
+	if location.identity == repoPath {
+		t.Fatalf("identity = %q, want the root commit", location.identity)
+	}
+
+	current, err := getCacheFilePath(location.identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := readCacheFile(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CacheFile{Repository: repoPath, Identity: location.identity, Values: CacheValues{"type": {Value: "feat"}}}
+	if !reflect.DeepEqual(*cache, want) {
+		t.Errorf("migrated cache = %+v, want %+v", *cache, want)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("cache file under the repository path was kept: %v", err)
+	}
+}
+
+func TestCacheShowAndClearLegacyFile(t *testing.T) {
+	useDataDir(t)
+	repoPath := initRepo(t, "feat: add login")
+	if err := os.WriteFile(filepath.Join(repoPath, ".commity.yaml"), []byte("cache:\n  identity: root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache file is still kept under the repository path, as before the identity strategy was changed
+	legacy, err := getCacheFilePath(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCacheFile(legacy, &CacheFile{Repository: repoPath, Values: CacheValues{"type": {Value: "feat"}, "scope": {Value: "api"}}}); err != nil {
+		t.Fatal(err)
+	}
+	current, err := getCacheFilePath(resolveCacheLocation(repoPath, "", config.IdentityRoot).identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runCacheShow([]string{"-directory", repoPath})
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("cache show migrated the cache file: %v", err)
+	}
+	if _, err := os.Stat(current); !os.IsNotExist(err) {
+		t.Errorf("cache show created the cache file of the current identity: %v", err)
+	}
+
+	runCacheClear([]string{"-directory", repoPath, "type"})
+	cache, err := readCacheFile(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (CacheValues{"scope": {Value: "api"}}); !reflect.DeepEqual(cache.Values, want) {
+		t.Errorf("values after clearing a key = %v, want %v", cache.Values, want)
+	}
+	if _, err := os.Stat(current); !os.IsNotExist(err) {
+		t.Errorf("cache clear created the cache file of the current identity: %v", err)
+	}
+
+	runCacheClear([]string{"-directory", repoPath})
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("cache clear kept the cache file under the repository path: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/michaelrampl/commity/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// runConfig implements the config command and dispatches its subcommands.
+func runConfig(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: commity config migrate [options] [file]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "migrate":
+		runConfigMigrate(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Unknown config command: %s", args[0])))
+		os.Exit(1)
+	}
+}
+
+// runConfigMigrate rewrites a configuration file in place using the newest version of the configuration format.
+// The file is modified on the YAML node level, so comments are preserved. A file that is already at the
+// newest version is not rewritten.
+func runConfigMigrate(args []string) {
+	flags := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	directory := flags.String("directory", "", "The directory to look up the configuration for")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity config migrate [options] [file]")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfgPath := flags.Arg(0)
+	if cfgPath == "" {
+		cfgPath = locateConfig(*directory)
+	}
+
+	if !config.IsYAML(cfgPath) {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Only YAML configuration files can be migrated in place, please update %s manually", cfgPath)))
+		os.Exit(1)
+	}
+
+	info, err := os.Stat(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading configuration: %v", err)))
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading configuration: %v", err)))
+		os.Exit(1)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error parsing configuration %s: %v", cfgPath, err)))
+		os.Exit(1)
+	}
+
+	root := doc.Content[0]
+	firstLine, hasVersion := 0, false
+	if len(root.Content) > 0 && root.Style&yaml.FlowStyle == 0 {
+		firstLine = root.Content[0].Line
+	}
+	for i := 0; i < len(root.Content); i += 2 {
+		hasVersion = hasVersion || root.Content[i].Value == "version"
+	}
+
+	warnings, changed, err := config.Migrate(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error migrating configuration %s: %v", cfgPath, err)))
+		os.Exit(1)
+	}
+	if !changed {
+		// Re-encoding would reformat the file, so a current file is left untouched
+		fmt.Println(style_success.Render(fmt.Sprintf("Configuration %s is already at version %d", cfgPath, config.CurrentVersion)))
+		return
+	}
+
+	var output []byte
+	if len(warnings) == 0 && !hasVersion && firstLine > 0 {
+		// Only the version field is missing, it is added without reformatting the file
+		output = insertLine(data, firstLine, fmt.Sprintf("version: %d", config.CurrentVersion))
+	} else {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&doc); err != nil {
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error encoding configuration: %v", err)))
+			os.Exit(1)
+		}
+		encoder.Close()
+		output = buf.Bytes()
+	}
+
+	if err := os.WriteFile(cfgPath, output, info.Mode().Perm()); err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error writing configuration: %v", err)))
+		os.Exit(1)
+	}
+
+	for _, warning := range warnings {
+		fmt.Println(warning.Format(cfgPath))
+	}
+	fmt.Println(style_success.Render(fmt.Sprintf("Migrated %s to version %d", cfgPath, config.CurrentVersion)))
+}
+
+// insertLine inserts a line before the given line (starting at 1) of the data, using the line endings of the data.
+func insertLine(data []byte, line int, content string) []byte {
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			offset = len(data)
+			break
+		}
+		offset += next + 1
+	}
+
+	result := make([]byte, 0, len(data)+len(content)+len(newline))
+	result = append(result, data[:offset]...)
+	result = append(result, content+newline...)
+	return append(result, data[offset:]...)
+}
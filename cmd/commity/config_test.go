@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigMigrate(t *testing.T) {
+	entries := `
+entries:
+  # The kind of change
+  - type: Choice
+    name: type
+    choices:
+      - value: feat
+        label: "A new feature"
+
+  - type: Text
+    name: subject   # kept short
+    maxLength: 50
+
+template: "{{ .type }}: {{ .subject }}"
+`
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "current file is kept",
+			content: "# Commit message configuration\nversion: 1\n" + entries,
+			want:    "# Commit message configuration\nversion: 1\n" + entries,
+		},
+		{
+			name:    "version is added without reformatting",
+			content: "# Commit message configuration\n" + entries,
+			want:    "# Commit message configuration\n\nversion: 1\n" + entries[1:],
+		},
+		{
+			name:    "version is added before a commented first field",
+			content: "# The format\ntemplate: x\n\noverview: true\n",
+			want:    "# The format\nversion: 1\ntemplate: x\n\noverview: true\n",
+		},
+		{
+			name:    "windows line endings",
+			content: "template: x\r\n\r\noverview: true\r\n",
+			want:    "version: 1\r\ntemplate: x\r\n\r\noverview: true\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".commity.yaml")
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			runConfigMigrate([]string{path})
+
+			output, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != test.want {
+				t.Errorf("config migrate wrote %q, want %q", output, test.want)
+			}
+		})
+	}
+}
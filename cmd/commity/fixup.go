@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/michaelrampl/commity/internal/utils"
+)
+
+// defaultFixupLimit is the default number of recent commits offered as fixup target.
+const defaultFixupLimit = 50
+
+// runFixup implements the fixup command.
+// It lets the user pick a recent commit and commits the staged changes as fixup!, squash! or amend! commit for it,
+// without showing the regular form.
+func runFixup(args []string) {
+	flags := flag.NewFlagSet("fixup", flag.ExitOnError)
+	directory := flags.String("directory", "", "The directory to run commity in")
+	limit := flags.Int("limit", defaultFixupLimit, "The number of recent commits to choose from")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity fixup [options]")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	repoPath, err := utils.FindGitRepository(resolveDirectory(*directory))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error findig git repository: %v", err)))
+		os.Exit(1)
+	}
+
+	stagedFiles, err := utils.GetStagedFiles(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking added files: %v", err)))
+		os.Exit(1)
+	}
+	if stagedFiles == 0 {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Nothing to commit in %v", repoPath)))
+		os.Exit(1)
+	}
+
+	commits, err := utils.GetRecentCommits(repoPath, *limit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading recent commits: %v", err)))
+		os.Exit(1)
+	}
+	if len(commits) == 0 {
+		fmt.Fprintln(os.Stderr, style_error.Render("There are no commits to fix up"))
+		os.Exit(1)
+	}
+
+	gitUserName, gitUserEmail, err := utils.GetGitIdentity(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error getting git identity: %v", err)))
+		os.Exit(1)
+	}
+
+	var options []huh.Option[*object.Commit]
+	for _, commit := range commits {
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		options = append(options, huh.NewOption(fmt.Sprintf("%s %s", commit.Hash.String()[:7], subject), commit))
+	}
+
+	var target *object.Commit
+	kind := utils.FixupKinds[0]
+	var message string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[*object.Commit]().
+				Title("Commit").
+				Description("The commit to fold the staged changes into (type / to search)").
+				Options(options...).
+				Filtering(true).
+				Value(&target),
+			huh.NewSelect[string]().
+				Title("Kind").
+				Options(
+					huh.NewOption("fixup! - Keep the message of the commit", "fixup"),
+					huh.NewOption("squash! - Combine both messages", "squash"),
+					huh.NewOption("amend! - Replace the message of the commit", "amend"),
+				).
+				Value(&kind),
+		),
+		huh.NewGroup(huh.NewText().
+			Title("Message").
+			DescriptionFunc(func() string {
+				if kind == "amend" {
+					return "The new message of the commit"
+				}
+				return "Appended to the message of the commit when squashing (optional)"
+			}, &kind).
+			Value(&message).
+			Validate(func(input string) error {
+				if kind == "amend" && strings.TrimSpace(input) == "" {
+					return errors.New("Input must not be empty")
+				}
+				return nil
+			}),
+		).WithHideFunc(func() bool { return kind == "fixup" }),
+	).WithTheme(getTheme())
+
+	if err := form.Run(); err != nil {
+		if err == huh.ErrUserAborted {
+			fmt.Println(style_warning.Render("Commit Canceled - Goodbye!"))
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
+		os.Exit(1)
+	}
+
+	if kind == "fixup" {
+		message = ""
+	}
+	msg := utils.FixupMessage(kind, target, message)
+	if err := utils.Commit(repoPath, msg, gitUserName, gitUserEmail); err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error while doing commit: %v", err)))
+		os.Exit(1)
+	}
+
+	paramStyle := lipgloss.NewStyle().Bold(true)
+	fmt.Println(style_success.Render("Success!"))
+	fmt.Printf("Commited Files: %s\nRepository: %s\nIdentity: %s <%s>\n---\n%s", paramStyle.Render(fmt.Sprint(stagedFiles)), paramStyle.Render(repoPath), paramStyle.Render(gitUserName), paramStyle.Render(gitUserEmail), msg)
+	// The root commit has no parent to rebase onto, so the whole history is rebased
+	base := target.Hash.String()[:7] + "~1"
+	if target.NumParents() == 0 {
+		base = "--root"
+	}
+	fmt.Println(style_warning.Render(fmt.Sprintf("Run git rebase -i --autosquash %s to fold it into the commit", base)))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/michaelrampl/commity/internal/config"
+	"github.com/michaelrampl/commity/internal/utils"
+)
+
+// guardViolationPaths returns the distinct paths of the violations in order.
+func guardViolationPaths(violations []utils.GuardViolation) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, violation := range violations {
+		if !seen[violation.Path] {
+			seen[violation.Path] = true
+			paths = append(paths, violation.Path)
+		}
+	}
+	return paths
+}
+
+// guardMessages returns the messages of the violations by path.
+func guardMessages(violations []utils.GuardViolation) map[string][]string {
+	messages := make(map[string][]string)
+	for _, violation := range violations {
+		messages[violation.Path] = append(messages[violation.Path], violation.Message)
+	}
+	return messages
+}
+
+// guardOverview returns the lines listing the files unstaged because of guard violations in the Overview.
+func guardOverview(violations []utils.GuardViolation, unstaged []string) string {
+	if len(unstaged) == 0 {
+		return ""
+	}
+	messages := guardMessages(violations)
+	lines := []string{"Unstaged by Guards:"}
+	for _, path := range unstaged {
+		lines = append(lines, fmt.Sprintf("  %s (%s)", path, strings.Join(messages[path], ", ")))
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// resolveGuards shows the files violating the guards and offers to unstage them before the form is shown,
+// so the form only runs for the files that are committed. All files are selected initially. The guards are
+// checked again after unstaging; if violations remain or the user cancels, commity exits.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - guards: The guards to check.
+// - violations: The guard violations of the staged files.
+// - paths: The files committed, nil if all staged files are committed.
+//
+// Returns:
+// - The files committed without the unstaged ones, nil if all staged files are committed.
+// - The files unstaged.
+func resolveGuards(repoPath string, guards config.Guards, violations []utils.GuardViolation, paths []string) ([]string, []string) {
+	messages := guardMessages(violations)
+
+	var options []huh.Option[string]
+	for _, path := range guardViolationPaths(violations) {
+		label := fmt.Sprintf("%s (%s)", path, strings.Join(messages[path], ", "))
+		options = append(options, huh.NewOption(label, path).Selected(true))
+	}
+
+	var unstage []string
+	form := huh.NewForm(huh.NewGroup(huh.NewMultiSelect[string]().
+		Title("Unstage Files").
+		Description("These staged files violate the guards. The commit is blocked unless they are unstaged.").
+		Options(options...).
+		Value(&unstage),
+	)).WithTheme(getTheme())
+
+	if err := form.Run(); err != nil {
+		if err == huh.ErrUserAborted {
+			fmt.Println(style_warning.Render("Commit Canceled - Goodbye!"))
+			exit(1)
+		}
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
+		exit(1)
+	}
+
+	if err := utils.UnstageFiles(repoPath, unstage); err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error unstaging files: %v", err)))
+		exit(1)
+	}
+	if paths != nil {
+		// An empty list still restricts the commit, unlike nil
+		remaining := make([]string, 0, len(paths))
+		for _, path := range paths {
+			if !contains(unstage, path) {
+				remaining = append(remaining, path)
+			}
+		}
+		paths = remaining
+	}
+
+	violations, err := utils.CheckGuards(repoPath, guards, paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking staged files: %v", err)))
+		exit(1)
+	}
+	if len(violations) > 0 {
+		reportGuardViolations(violations)
+		exit(1)
+	}
+	return paths, unstage
+}
+
+// reportGuardViolations prints the guard violations blocking the commit.
+func reportGuardViolations(violations []utils.GuardViolation) {
+	fmt.Fprintln(os.Stderr, style_error.Render("Commit blocked: staged files violate the guards"))
+	for _, violation := range violations {
+		fmt.Fprintln(os.Stderr, violation.String())
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/michaelrampl/commity/internal/utils"
+)
+
+func TestGuardOverview(t *testing.T) {
+	violations := []utils.GuardViolation{
+		{Path: ".env", Message: "forbidden path"},
+		{Path: "data.bin", Message: "forbidden path"},
+		{Path: "data.bin", Message: "larger than 1MB"},
+	}
+
+	tests := []struct {
+		name     string
+		unstaged []string
+		want     string
+	}{
+		{name: "nothing unstaged"},
+		{name: "one file", unstaged: []string{".env"}, want: "\nUnstaged by Guards:\n  .env (forbidden path)"},
+		{name: "several violations", unstaged: []string{".env", "data.bin"}, want: "\nUnstaged by Guards:\n  .env (forbidden path)\n  data.bin (forbidden path, larger than 1MB)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := guardOverview(violations, test.unstaged); got != test.want {
+				t.Errorf("guardOverview() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/michaelrampl/commity/internal/config"
+	"github.com/michaelrampl/commity/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// maxHistoryRecords is the number of messages kept in the history of a repository.
+const maxHistoryRecords = 50
+
+// HistoryRecord is a commit message together with the entry values it was rendered from.
+// Drafts use the same format, with an empty message if the form was not completed.
+type HistoryRecord struct {
+	Time      time.Time `yaml:"time"`              // When the record was created
+	Branch    string    `yaml:"branch,omitempty"`  // The branch checked out at the time
+	Message   string    `yaml:"message,omitempty"` // The rendered commit message
+	Values    ParamMap  `yaml:"values"`            // The values of the entries
+	Committed bool      `yaml:"committed"`         // Whether the commit was created successfully
+}
+
+// entryValues returns the values of all entries that are entered in the form, formatted like the cache.
+// Number entries are taken from their input fields, as they are only parsed once the form is completed.
+func entryValues(entries []config.Entry, numberInputs map[*config.NumberEntry]*string) ParamMap {
+	values := make(ParamMap)
+	for _, entry := range entries {
+		switch e := entry.(type) {
+		case *config.TextEntry:
+			values[e.Name] = e.ApplyTransforms(e.Value)
+		case *config.ChoiceEntry:
+			values[e.Name] = e.Value
+		case *config.BooleanEntry:
+			values[e.Name] = fmt.Sprint(e.Value)
+		case *config.NumberEntry:
+			if input, ok := numberInputs[e]; ok {
+				values[e.Name] = *input
+			} else {
+				values[e.Name] = e.FormatValue(e.Value)
+			}
+		}
+	}
+	return values
+}
+
+// loadHistory reads the message history of the repository with the given identity (see cacheIdentity),
+// most recent record first.
+func loadHistory(identity string) ([]HistoryRecord, error) {
+	historyFile, err := getRepoDataFilePath("history", identity)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(historyFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// No history yet
+			return nil, nil
+		}
+		return nil, err
+	}
+	var history []HistoryRecord
+	if err := yaml.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// appendHistory adds a record to the message history of the repository, dropping the oldest records
+// beyond maxHistoryRecords. Failures are reported as warnings.
+func appendHistory(record HistoryRecord, identity string) {
+	// Concurrent runs in several worktrees share the history, read and write it under the cache lock
+	unlock, err := lockCache()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save message history: %v", err)))
+		return
+	}
+	defer unlock()
+
+	history, err := loadHistory(identity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to load message history: %v", err)))
+	}
+	history = append([]HistoryRecord{record}, history...)
+	if len(history) > maxHistoryRecords {
+		history = history[:maxHistoryRecords]
+	}
+	if err := writeRepoDataFile("history", identity, history); err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save message history: %v", err)))
+	}
+}
+
+// loadDraft reads the draft of the repository with the given identity, or returns nil if there is none.
+func loadDraft(identity string) (*HistoryRecord, error) {
+	draftFile, err := getRepoDataFilePath("drafts", identity)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(draftFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var draft HistoryRecord
+	if err := yaml.Unmarshal(data, &draft); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// saveDraft replaces the draft of the repository. Failures are reported as warnings.
+func saveDraft(draft HistoryRecord, identity string) {
+	if err := writeRepoDataFile("drafts", identity, draft); err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save draft: %v", err)))
+	}
+}
+
+// deleteDraft removes the draft of the repository after a successful commit.
+func deleteDraft(identity string) {
+	draftFile, err := getRepoDataFilePath("drafts", identity)
+	if err != nil {
+		return
+	}
+	if err := os.Remove(draftFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to delete draft: %v", err)))
+	}
+}
+
+// writeRepoDataFile persists a value as YAML into the repository-specific file of the given data subdirectory.
+func writeRepoDataFile(subDir string, repoPath string, value interface{}) error {
+	file, err := getRepoDataFilePath(subDir, repoPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(file, data, 0644)
+}
+
+// offerDraft asks whether the draft should be restored.
+func offerDraft(draft *HistoryRecord) bool {
+	preview := strings.TrimSpace(draft.Message)
+	if preview == "" {
+		var lines []string
+		for name, value := range draft.Values {
+			if value != "" {
+				lines = append(lines, fmt.Sprintf("%s: %s", name, strings.ReplaceAll(value, "\n", " ")))
+			}
+		}
+		sort.Strings(lines)
+		preview = strings.Join(lines, "\n")
+	}
+
+	restore := true
+	form := huh.NewForm(huh.NewGroup(
+		huh.NewConfirm().
+			Title(fmt.Sprintf("Restore the draft from %s?", draft.Time.Local().Format("2006-01-02 15:04"))).
+			Description(preview).
+			Affirmative("Restore").
+			Negative("Start over").
+			Value(&restore),
+	)).WithTheme(getTheme())
+
+	if err := form.Run(); err != nil {
+		if err == huh.ErrUserAborted {
+			fmt.Println(style_warning.Render("Commit Canceled - Goodbye!"))
+			exit(1)
+		}
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
+		exit(1)
+	}
+	return restore
+}
+
+// applyDraft sets the values of the draft in the parameter map, keeping values set explicitly.
+func applyDraft(paramMap ParamMap, draft *HistoryRecord) {
+	for name, value := range draft.Values {
+		if _, exists := paramMap[name]; !exists {
+			paramMap[name] = value
+		}
+	}
+}
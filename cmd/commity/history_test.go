@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+func TestDraftRoundTrip(t *testing.T) {
+	useDataDir(t)
+	repoPath := t.TempDir()
+
+	if draft, err := loadDraft(repoPath); err != nil || draft != nil {
+		t.Fatalf("loadDraft() = %v, %v, want no draft", draft, err)
+	}
+
+	draft := HistoryRecord{
+		Time:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Branch:  "feature/login",
+		Message: "feat: add login\n\nSupports OAuth.\n",
+		Values:  ParamMap{"type": "feat", "subject": "add login", "body": "Supports OAuth.\n", "breaking": "false"},
+	}
+	saveDraft(draft, repoPath)
+
+	loaded, err := loadDraft(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded == nil || !loaded.Time.Equal(draft.Time) {
+		t.Fatalf("loadDraft() = %v, want %v", loaded, draft)
+	}
+	loaded.Time = draft.Time
+	if !reflect.DeepEqual(*loaded, draft) {
+		t.Errorf("loadDraft() = %v, want %v", *loaded, draft)
+	}
+
+	// Drafts are kept per repository
+	if other, err := loadDraft(t.TempDir()); err != nil || other != nil {
+		t.Errorf("loadDraft() of another repository = %v, %v, want no draft", other, err)
+	}
+
+	deleteDraft(repoPath)
+	if draft, err := loadDraft(repoPath); err != nil || draft != nil {
+		t.Errorf("loadDraft() after deleteDraft() = %v, %v, want no draft", draft, err)
+	}
+	// Deleting a missing draft is not an error
+	deleteDraft(repoPath)
+}
+
+func TestAppendHistory(t *testing.T) {
+	useDataDir(t)
+	repoPath := t.TempDir()
+
+	for i := 0; i < maxHistoryRecords+2; i++ {
+		appendHistory(HistoryRecord{Message: fmt.Sprintf("commit %d", i), Committed: true}, repoPath)
+	}
+
+	history, err := loadHistory(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != maxHistoryRecords {
+		t.Fatalf("len(history) = %d, want %d", len(history), maxHistoryRecords)
+	}
+	if first, last := history[0].Message, history[len(history)-1].Message; first != "commit 51" || last != "commit 2" {
+		t.Errorf("history ranges from %q to %q, want from %q to %q", first, last, "commit 51", "commit 2")
+	}
+}
+
+func TestApplyDraft(t *testing.T) {
+	paramMap := ParamMap{"type": "fix"}
+	applyDraft(paramMap, &HistoryRecord{Values: ParamMap{"type": "feat", "subject": "add login"}})
+
+	if want := (ParamMap{"type": "fix", "subject": "add login"}); !reflect.DeepEqual(paramMap, want) {
+		t.Errorf("applyDraft() = %v, want %v", paramMap, want)
+	}
+}
+
+func TestEntryValues(t *testing.T) {
+	points := &config.NumberEntry{Name: "points", Integer: true, Value: 3}
+	estimate := &config.NumberEntry{Name: "estimate", Value: 1.5}
+	input := "4"
+	entries := []config.Entry{
+		&config.TextEntry{Name: "subject", Value: "add login"},
+		&config.ChoiceEntry{Name: "type", Value: "feat"},
+		&config.BooleanEntry{Name: "breaking", Value: true},
+		points,
+		estimate,
+		&config.ComputedEntry{Name: "header", Value: "feat: add login"},
+	}
+
+	got := entryValues(entries, map[*config.NumberEntry]*string{points: &input})
+	want := ParamMap{"subject": "add login", "type": "feat", "breaking": "true", "points": "4", "estimate": "1.5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entryValues() = %v, want %v", got, want)
+	}
+}
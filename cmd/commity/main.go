@@ -5,14 +5,15 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/michaelrampl/commity/internal/config"
 	"github.com/michaelrampl/commity/internal/utils"
-	"gopkg.in/yaml.v3"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
@@ -38,6 +39,22 @@ var style_warning = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
 
 var style_success = lipgloss.NewStyle().Foreground(colorHighlight)
 
+// exitHandlers are run before commity exits, the last registered first.
+var exitHandlers []func()
+
+// onExit registers a function run when commity exits early, e.g. to undo changes made for a commit that is not created.
+func onExit(handler func()) {
+	exitHandlers = append(exitHandlers, handler)
+}
+
+// exit runs the registered exit handlers and terminates commity with the given status code.
+func exit(code int) {
+	for i := len(exitHandlers) - 1; i >= 0; i-- {
+		exitHandlers[i]()
+	}
+	os.Exit(code)
+}
+
 type ParamMap map[string]string
 
 func (m *ParamMap) String() string {
@@ -53,126 +70,147 @@ func (m *ParamMap) Set(value string) error {
 	return nil
 }
 
-// getStoredKeys returns a dictionary mapping each configuration entry name
-// to its store flag (true if the entry should be persisted).
-func getStoredKeys(entries *[]config.Entry) map[string]bool {
-	storeDict := make(map[string]bool)
+// storedEntry describes how the value of an entry with `store: true` is kept between runs.
+type storedEntry struct {
+	scope config.StoreScope // The scope the value is stored in
+	ttl   time.Duration     // How long the value is restored (0 = no expiry)
+}
+
+// getStoredKeys returns a dictionary mapping the name of each configuration entry
+// that should be persisted to how its value is stored.
+func getStoredKeys(entries *[]config.Entry) map[string]storedEntry {
+	storeDict := make(map[string]storedEntry)
 	for _, entry := range *entries {
 		switch e := entry.(type) {
 		case *config.TextEntry:
-			storeDict[e.Name] = e.Store
+			if e.Store {
+				storeDict[e.Name] = storedEntry{e.StoreScope.OrDefault(), time.Duration(e.StoreTTL)}
+			}
 		case *config.ChoiceEntry:
-			storeDict[e.Name] = e.Store
+			if e.Store {
+				storeDict[e.Name] = storedEntry{e.StoreScope.OrDefault(), time.Duration(e.StoreTTL)}
+			}
 		case *config.BooleanEntry:
-			storeDict[e.Name] = e.Store
+			if e.Store {
+				storeDict[e.Name] = storedEntry{e.StoreScope.OrDefault(), time.Duration(e.StoreTTL)}
+			}
+		case *config.NumberEntry:
+			if e.Store {
+				storeDict[e.Name] = storedEntry{e.StoreScope.OrDefault(), time.Duration(e.StoreTTL)}
+			}
 		}
 	}
 	return storeDict
 }
 
-// getCacheFilePath returns the repository-specific cache file path.
-// It places the file inside a "cache" subfolder within the data directory
-// and uses the MD5 hash of the repository path (in hexadecimal) as the file name.
-func getCacheFilePath(repoPath string) (string, error) {
+// getRepoDataFilePath returns the path of a repository-specific file within a subfolder of the data directory.
+// It uses the MD5 hash of the repository path (in hexadecimal) as the file name.
+func getRepoDataFilePath(subDir string, repoPath string) (string, error) {
 	dataDir, err := utils.GetDataDir()
 	if err != nil {
 		return "", err
 	}
-	// Define the cache subfolder.
-	cacheDir := filepath.Join(dataDir, "cache")
 	// Compute the MD5 hash of repoPath inline.
 	hash := md5.Sum([]byte(repoPath))
 	repoID := fmt.Sprintf("%x", hash)
 	fileName := repoID + ".yaml"
-	return filepath.Join(cacheDir, fileName), nil
+	return filepath.Join(dataDir, subDir, fileName), nil
 }
 
-// loadParamMapFromFile loads the persisted parameters from the repository-specific cache file.
-func loadParamMapFromFile(repoPath string) (ParamMap, error) {
-	cacheFile, err := getCacheFilePath(repoPath)
-	if err != nil {
-		return nil, err
-	}
-	data, err := os.ReadFile(cacheFile)
+// restoreParamMap merges file-loaded parameters into the provided paramMap,
+// but only for keys that are marked as stored and only when the key is not already set
+// (so that CLI–provided values always win). Values are looked up in the scope of their entry first,
+// then in the broader scopes listed in storeFallbacks. Values older than the storeTTL of their entry are ignored.
+func restoreParamMap(paramMap *ParamMap, storedKeys map[string]storedEntry, location cacheLocation) {
+	// Load persisted parameters from file.
+	cache, global, err := loadCaches(location.identity)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			// No persisted file yet; return an empty map.
-			return ParamMap{}, nil
+		// If loading fails, proceed with empty caches.
+		cache, global = &CacheFile{}, &CacheFile{}
+	}
+	now := time.Now()
+	for key, stored := range storedKeys {
+		// Only merge if the key is not already set in the in-memory map.
+		if _, exists := (*paramMap)[key]; exists {
+			continue
+		}
+		for _, fallback := range storeFallbacks[stored.scope] {
+			file := cache
+			if fallback == config.ScopeGlobal {
+				file = global
+			}
+			if val, ok := file.partition(fallback, location, false)[key]; ok && !val.expired(stored.ttl, now) {
+				(*paramMap)[key] = val.Value
+				break
+			}
 		}
-		return nil, err
-	}
-	var pm ParamMap
-	if err := yaml.Unmarshal(data, &pm); err != nil {
-		return nil, err
 	}
-	return pm, nil
 }
 
-// saveParamMapToFile persists the parameter map as YAML into the repository-specific cache file.
-// It ensures that the cache directory is created if it doesn't exist.
-func saveParamMapToFile(paramMap ParamMap, repoPath string) error {
-	cacheFile, err := getCacheFilePath(repoPath)
-	if err != nil {
-		return err
-	}
-	// Ensure the cache directory exists.
-	cacheDir := filepath.Dir(cacheFile)
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return err
-	}
-	data, err := yaml.Marshal(paramMap)
+// updateParamMap stores the values of the configuration entries (cfg.Entries) marked for storage in storedKeys,
+// each in the partition of its scope, and refreshes their timestamps. Values stored for other entries,
+// branches or worktrees are kept. The cache is read and written while holding the cache lock,
+// so values written by concurrent runs in the meantime are merged rather than overwritten.
+func updateParamMap(entries *[]config.Entry, storedKeys map[string]storedEntry, location cacheLocation) {
+	unlock, err := lockCache()
 	if err != nil {
-		return err
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save parameter map: %v", err)))
+		return
 	}
-	return os.WriteFile(cacheFile, data, 0644)
-}
+	defer unlock()
 
-// restoreParamMap merges file-loaded parameters into the provided paramMap,
-// but only for keys that are marked as stored (i.e. storedKeys[key] is true)
-// and only when the key is not already set (so that CLI–provided values always win).
-func restoreParamMap(paramMap *ParamMap, storedKeys map[string]bool, repoPath string) {
-	// Load persisted parameters from file.
-	fileParams, err := loadParamMapFromFile(repoPath)
+	cache, global, err := loadCaches(location.identity)
 	if err != nil {
-		// If loading fails, proceed with an empty map.
-		fileParams = ParamMap{}
-	}
-	// For each key marked for storage...
-	for key, store := range storedKeys {
-		if store {
-			// Only merge if the key is not already set in the in-memory map.
-			if _, exists := (*paramMap)[key]; !exists {
-				if val, ok := fileParams[key]; ok {
-					(*paramMap)[key] = val
-				}
-			}
-		}
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save parameter map: %v", err)))
+		return
 	}
-}
-
-// updateParamMap creates a new parameter map from the configuration entries (cfg.Entries)
-// but only for keys marked for storage in storedKeys, and then saves that map to file.
-func updateParamMap(entries *[]config.Entry, storedKeys map[string]bool, repoPath string) {
-	newMap := make(ParamMap)
+	globalChanged := false
+	now := time.Now()
 	for _, entry := range *entries {
 		name := entry.GetName()
-		if stored, ok := storedKeys[name]; ok && stored {
-			switch e := entry.(type) {
-			case *config.TextEntry:
-				newMap[name] = e.Value
-			case *config.ChoiceEntry:
-				newMap[name] = e.Value
-			case *config.BooleanEntry:
-				if e.Value {
-					newMap[name] = "true"
-				} else {
-					newMap[name] = "false"
-				}
+		stored, ok := storedKeys[name]
+		if !ok {
+			continue
+		}
+		file := cache
+		if stored.scope == config.ScopeGlobal {
+			file = global
+			globalChanged = true
+		}
+		values := file.partition(stored.scope, location, true)
+		if values == nil {
+			continue
+		}
+		switch e := entry.(type) {
+		case *config.TextEntry:
+			values[name] = CachedValue{e.ApplyTransforms(e.Value), now}
+		case *config.ChoiceEntry:
+			values[name] = CachedValue{e.Value, now}
+		case *config.BooleanEntry:
+			if e.Value {
+				values[name] = CachedValue{"true", now}
+			} else {
+				values[name] = CachedValue{"false", now}
 			}
+		case *config.NumberEntry:
+			values[name] = CachedValue{e.FormatValue(e.Value), now}
 		}
 	}
-	// Persist the new map.
-	if err := saveParamMapToFile(newMap, repoPath); err != nil {
+
+	// Persist the caches.
+	cacheFile, err := getCacheFilePath(location.identity)
+	if err == nil {
+		cache.Repository = location.repository
+		cache.Identity = cacheFileIdentity(location)
+		err = writeCacheFile(cacheFile, cache)
+	}
+	if err == nil && globalChanged {
+		var globalFile string
+		if globalFile, err = getGlobalCacheFilePath(); err == nil {
+			err = writeCacheFile(globalFile, global)
+		}
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save parameter map: %v", err)))
 	}
 }
@@ -240,84 +278,248 @@ func validateInput(value string, minLength, maxLength int, pattern string, patte
 	return nil
 }
 
-func runCommity(directory string, paramMap ParamMap) {
+// validateText checks the input of a text entry against all of its constraints and returns the first blocking problem.
+func validateText(e *config.TextEntry, input string, dict config.Dictionary) error {
+	value := e.ApplyTransforms(input)
+	if err := validateInput(value, e.MinLength, e.MaxLength, e.Pattern, e.PatternHint); err != nil {
+		return err
+	}
+	for _, finding := range e.Lint(value, dict) {
+		if !finding.Warning {
+			return errors.New(finding.Message)
+		}
+	}
+	return nil
+}
 
-	repoPath, err := utils.FindGitRepository(directory)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error findig git repository: %v", err)))
-		os.Exit(1)
+// textDescription returns the description of a text entry followed by the warnings about its current value.
+func textDescription(e *config.TextEntry, dict config.Dictionary) string {
+	var warnings []string
+	for _, finding := range e.Lint(e.ApplyTransforms(e.Value), dict) {
+		if finding.Warning {
+			warnings = append(warnings, finding.Message)
+		}
+	}
+	if len(warnings) == 0 {
+		return e.Description
+	}
+	return strings.TrimSpace(e.Description + "\n" + style_warning.Render(strings.Join(warnings, "\n")))
+}
+
+// ruleViolationGroups builds the form shown when rules are violated: a note listing the violations,
+// followed by the groups of the fields the violations refer to.
+// If a violation does not refer to a field shown in the form, all fields are shown again.
+func ruleViolationGroups(violations []utils.RuleViolation, entries []config.Entry, entryGroups map[string]*huh.Group) []*huh.Group {
+	var messages []string
+	fields := make(map[string]bool)
+	all := false
+	for _, violation := range violations {
+		messages = append(messages, "- "+violation.Message)
+		if entryGroups[violation.Field] == nil {
+			all = true
+		}
+		fields[violation.Field] = true
 	}
 
+	groups := []*huh.Group{huh.NewGroup(huh.NewNote().
+		Title("Rules Violated").
+		Description(strings.Join(messages, "\n")),
+	)}
+	for _, entry := range entries {
+		if group := entryGroups[entry.GetName()]; group != nil && (all || fields[entry.GetName()]) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// commitOptions adjusts a run of runCommity.
+type commitOptions struct {
+	paths      []string        // Only commit the staged changes of these files, all others remain staged (all files if nil)
+	resume     bool            // Restore the draft of the repository without asking
+	skipDraft  bool            // Neither offer, restore nor save the draft, e.g. because the form is prefilled
+	prefilled  map[string]bool // Entries prefilled by commity rather than the user, their values are not stored for the next run
+	keepStaged bool            // Guard violations block the commit instead of offering to unstage the files
+	resumeCmd  string          // The command restoring the draft after a canceled or failed commit, shown as hint
+}
+
+// countStagedFiles returns the number of files committed, exiting if there is nothing to commit.
+func countStagedFiles(repoPath string, paths []string) int {
 	stagedFiles, err := utils.GetStagedFiles(repoPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking added files: %v", err)))
-		os.Exit(1)
+		exit(1)
+	}
+	if paths != nil {
+		stagedFiles = len(paths)
 	}
 	if stagedFiles == 0 {
 		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Nothing to commit in %v", repoPath)))
-		os.Exit(1)
+		exit(1)
 	}
+	return stagedFiles
+}
+
+// runCommity shows the form and commits the staged changes with the rendered message.
+// It returns the files committed, which are nil if all staged files were committed.
+func runCommity(directory string, paramMap ParamMap, options commitOptions) []string {
+	paths := options.paths
+
+	repoPath, err := utils.FindGitRepository(directory)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error findig git repository: %v", err)))
+		exit(1)
+	}
+
+	stagedFiles := countStagedFiles(repoPath, paths)
 
 	// Load the configuration file
 	cfg, cfgPath, err := utils.LoadConfig(repoPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error loading configuration: %v", err)))
-		os.Exit(1)
+		exit(1)
+	}
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: %s (run commity config migrate to update the file)", warning)))
 	}
 
 	if len(cfg.Entries) == 0 || cfg.Template == "" {
 		fmt.Fprintln(os.Stderr, style_error.Render("Invalid configuration: no entries or template provided"))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// Apply the overrides for the current branch before the form is built
+	branch, err := utils.GetCurrentBranch(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading current branch: %v", err)))
+		exit(1)
+	}
+	if err := cfg.ApplyBranch(branch); err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error applying branch overrides: %v", err)))
+		exit(1)
+	}
+
+	// Check the files committed now against the guards and resolve violations before anything is entered
+	guardViolations, err := utils.CheckGuards(repoPath, cfg.Guards, paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking staged files: %v", err)))
+		exit(1)
+	}
+	var unstagedFiles []string
+	if len(guardViolations) > 0 && options.keepStaged {
+		reportGuardViolations(guardViolations)
+		exit(1)
+	}
+	if len(guardViolations) > 0 {
+		paths, unstagedFiles = resolveGuards(repoPath, cfg.Guards, guardViolations, paths)
+		stagedFiles = countStagedFiles(repoPath, paths)
+	}
+
+	// Check the staged changes for secrets before anything is entered
+	secretOverride := ""
+	if cfg.Secrets != nil {
+		var skip []string
+		if paths != nil {
+			skip = unselectedPaths(repoPath, paths)
+		}
+		secretOverride = checkSecrets(repoPath, cfg.Secrets, skip)
 	}
 
 	storedKeys := getStoredKeys(&cfg.Entries)
+	for name := range storedKeys {
+		// Hidden entries keep their default and prefilled entries a generated value, neither must replace the stored value
+		if cfg.IsHidden(name) || options.prefilled[name] {
+			delete(storedKeys, name)
+		}
+	}
+
+	// Drafts, history and suggestions are kept under the identity of the repository like the stored values
+	location := newCacheLocation(repoPath, branch, cfg.Cache.Identity)
+
+	// Restore the last draft before the stored values, so its values take precedence
+	if !options.skipDraft {
+		draft, err := loadDraft(location.identity)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to load draft: %v", err)))
+		}
+		switch {
+		case draft != nil && (options.resume || offerDraft(draft)):
+			applyDraft(paramMap, draft)
+		case draft == nil && options.resume:
+			fmt.Fprintln(os.Stderr, style_warning.Render("Warning: there is no draft to resume"))
+		}
+	}
 
 	if len(storedKeys) > 0 {
-		restoreParamMap(&paramMap, storedKeys, repoPath)
+		restoreParamMap(&paramMap, storedKeys, location)
 	}
 
 	gitUserName, gitUserEmail, err := utils.GetGitIdentity(repoPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error getting git identity: %v", err)))
-		os.Exit(1)
+		exit(1)
 	}
 
 	var groups []*huh.Group
 
+	suggestionHistory, err := loadSuggestionHistory(location.identity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to load suggestion history: %v", err)))
+	}
+
+	// Number entries are edited as text and converted once the form is completed
+	numberInputs := make(map[*config.NumberEntry]*string)
+	entryGroups := make(map[string]*huh.Group)
+
 	paramStyle := lipgloss.NewStyle().Bold(true)
 
 	if cfg.Overview {
+		overview := fmt.Sprintf("Staged Files: %s\nRepository: %s\nBranch: %s\nIdentity: %s <%s>\nCommity Config: %s", paramStyle.Render(fmt.Sprint(stagedFiles)), paramStyle.Render(repoPath), paramStyle.Render(branch), paramStyle.Render(gitUserName), paramStyle.Render(gitUserEmail), paramStyle.Render(cfgPath))
+		overview += guardOverview(guardViolations, unstagedFiles)
 		groups = append(groups, huh.NewGroup(huh.NewNote().
-			Title("Overview").Description(fmt.Sprintf("Staged Files: %s\nRepository: %s\nIdentity: %s <%s>\nCommity Config: %s", paramStyle.Render(fmt.Sprint(stagedFiles)), paramStyle.Render(repoPath), paramStyle.Render(gitUserName), paramStyle.Render(gitUserEmail), paramStyle.Render(cfgPath))),
+			Title("Overview").Description(overview),
 		))
 	}
 
 	for _, entry := range cfg.Entries {
+		if cfg.IsHidden(entry.GetName()) {
+			continue
+		}
 		switch e := entry.(type) {
 		case *config.TextEntry:
 			if paramMap[e.Name] != "" {
 				e.Value = paramMap[e.Name]
 			}
+			var dict config.Dictionary
+			if e.SpellCheck != nil {
+				dict, err = utils.LoadDictionary(e.SpellCheck)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: spell check of %s: %v", e.Name, err)))
+				}
+			}
 			if e.MultiLine {
 				group := huh.NewGroup(huh.NewText().
 					Value(&e.Value).
 					Title(e.Label).
-					Description(e.Description).
+					DescriptionFunc(func() string { return textDescription(e, dict) }, &e.Value).
 					Validate(func(input string) error {
-						return validateInput(input, e.MinLength, e.MaxLength, e.Pattern, e.PatternHint)
+						return validateText(e, input, dict)
 					}),
 				)
 				groups = append(groups, group)
+				entryGroups[e.Name] = group
 			} else {
 				group := huh.NewGroup(huh.NewInput().
 					Value(&e.Value).
 					Title(e.Label).
-					Description(e.Description).
+					DescriptionFunc(func() string { return textDescription(e, dict) }, &e.Value).
+					Suggestions(collectSuggestions(e, suggestionHistory[e.Name], repoPath)).
 					Validate(func(input string) error {
-						return validateInput(input, e.MinLength, e.MaxLength, e.Pattern, e.PatternHint)
+						return validateText(e, input, dict)
 					}),
 				)
 				groups = append(groups, group)
+				entryGroups[e.Name] = group
 			}
 
 		case *config.ChoiceEntry:
@@ -336,6 +538,7 @@ func runCommity(directory string, paramMap ParamMap) {
 				Options(options...),
 			)
 			groups = append(groups, group)
+			entryGroups[e.Name] = group
 		case *config.BooleanEntry:
 			if paramMap[e.Name] != "" {
 				if strings.ToLower(paramMap[e.Name]) == "true" || paramMap[e.Name] == "1" {
@@ -350,51 +553,202 @@ func runCommity(directory string, paramMap ParamMap) {
 				Description(e.Description),
 			)
 			groups = append(groups, group)
+			entryGroups[e.Name] = group
+		case *config.NumberEntry:
+			// Without a default the input starts at the minimum, or empty, instead of a 0 that may be invalid
+			var input string
+			switch {
+			case e.Default != nil:
+				input = e.FormatValue(e.Value)
+			case e.Min != nil:
+				input = e.FormatValue(*e.Min)
+			}
+			if paramMap[e.Name] != "" {
+				input = paramMap[e.Name]
+			}
+			numberInputs[e] = &input
+			group := huh.NewGroup(huh.NewInput().
+				Value(&input).
+				Title(e.Label).
+				Description(e.Description).
+				Validate(func(input string) error {
+					_, err := e.ParseValue(input)
+					return err
+				}),
+			)
+			groups = append(groups, group)
+			entryGroups[e.Name] = group
+		case *config.ComputedEntry:
+			// Computed entries are derived from the other entries when rendering the message
+			continue
 		default:
 			fmt.Fprintln(os.Stderr, style_error.Render("Unknown entry type"))
-			os.Exit(1)
+			exit(1)
 		}
 
 	}
 
-	form := huh.NewForm(groups...).WithTheme(getTheme())
+	// The entered values are kept as draft until the commit succeeds, so they can be restored after a failure
+	draft := func(message string) HistoryRecord {
+		return HistoryRecord{Time: time.Now(), Branch: branch, Message: message, Values: entryValues(cfg.Entries, numberInputs)}
+	}
 
-	err = form.Run()
-	if err != nil {
-		if err == huh.ErrUserAborted { // Check if the user canceled the form
-			fmt.Println(style_warning.Render("Commit Canceled - Goodbye!"))
-			os.Exit(1)
+	initialValues := entryValues(cfg.Entries, numberInputs)
+
+	runForm := func(groups []*huh.Group) {
+		form := huh.NewForm(groups...).WithTheme(getTheme())
+
+		err := form.Run()
+		// Only keep a draft if something was entered, a form aborted right away leaves the previous draft
+		saved := false
+		if record := draft(""); !options.skipDraft && !maps.Equal(record.Values, initialValues) {
+			saveDraft(record, location.identity)
+			saved = true
+		}
+		if err != nil {
+			if err == huh.ErrUserAborted { // Check if the user canceled the form
+				if !saved {
+					fmt.Println(style_warning.Render("Commit Canceled - Goodbye!"))
+				} else {
+					fmt.Println(style_warning.Render(fmt.Sprintf("Commit Canceled - Goodbye! (run %s to restore the draft)", options.resumeCmd)))
+				}
+				exit(1)
+			}
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
+			exit(1)
+		}
+
+		for e, input := range numberInputs {
+			e.Value, err = e.ParseValue(*input)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Invalid value for %s: %v", e.Name, err)))
+				exit(1)
+			}
 		}
-		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
-		os.Exit(1)
+	}
+
+	runForm(groups)
+
+	// Ask again for the fields violating a rule until all rules are satisfied
+	for {
+		violations, err := utils.CheckRules(cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking rules: %v", err)))
+			exit(1)
+		}
+		if len(violations) == 0 {
+			break
+		}
+		runForm(ruleViolationGroups(violations, cfg.Entries, entryGroups))
 	}
 
 	msg, err := utils.RenderCommitMessage(cfg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error rendering commit message: %v", err)))
-		os.Exit(1)
+		exit(1)
+	}
+	if secretOverride != "" {
+		msg = utils.AppendTrailer(msg, secretOverrideTrailer, secretOverride)
+	}
+	if !options.skipDraft {
+		saveDraft(draft(msg), location.identity)
 	}
 
-	err = utils.Commit(repoPath, msg, gitUserName, gitUserEmail)
+	if paths != nil {
+		err = utils.CommitPaths(repoPath, msg, gitUserName, gitUserEmail, paths)
+	} else {
+		err = utils.Commit(repoPath, msg, gitUserName, gitUserEmail)
+	}
+	record := draft(msg)
+	record.Committed = err == nil
+	appendHistory(record, location.identity)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error while doing commit: %v", err)))
-		os.Exit(1)
+		if !options.skipDraft {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("The message was saved as draft, run %s to restore it", options.resumeCmd)))
+		}
+		exit(1)
+	}
+	if !options.skipDraft {
+		deleteDraft(location.identity)
 	}
 
 	if len(storedKeys) > 0 {
-		updateParamMap(&cfg.Entries, storedKeys, repoPath)
+		updateParamMap(&cfg.Entries, storedKeys, location)
 	}
+	updateSuggestionHistory(&cfg.Entries, location.identity)
 
 	fmt.Println(style_success.Render("Success!"))
 	fmt.Printf("Commited Files: %s\nRepository: %s\nIdentity: %s <%s>\nCommity Config: %s\n---\n%s", paramStyle.Render(fmt.Sprint(stagedFiles)), paramStyle.Render(repoPath), paramStyle.Render(gitUserName), paramStyle.Render(gitUserEmail), paramStyle.Render(cfgPath), msg)
+	return paths
+}
+
+// resolveDirectory returns the absolute path of the given directory.
+// If the directory is empty, the current working directory is used.
+func resolveDirectory(directory string) string {
+	var dir = directory
+	var err error
+	if dir == "" {
+		dir, err = os.Getwd()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error getting current directory: %v", err)))
+			os.Exit(1)
+		}
+	}
+	abs_dir, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("failed to get absolute path: %v", err)))
+		os.Exit(1)
+	}
+	return abs_dir
+}
+
+// locateConfig returns the path of the configuration file a regular run in the given directory would load.
+func locateConfig(directory string) string {
+	dir := resolveDirectory(directory)
+	if repoPath, err := utils.FindGitRepository(dir); err == nil {
+		dir = repoPath
+	}
+	cfgPath, err := utils.FindConfigFile(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error locating configuration: %v", err)))
+		os.Exit(1)
+	}
+	return cfgPath
 }
 
 func main() {
 
+	// Dispatch subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		case "schema":
+			runSchema(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "fixup":
+			runFixup(os.Args[2:])
+			return
+		case "revert":
+			runRevert(os.Args[2:])
+			return
+		case "cache":
+			runCache(os.Args[2:])
+			return
+		}
+	}
+
 	// Define cmdline parameters
 	version := flag.Bool("version", false, "Print the version and exit")
 	help := flag.Bool("help", false, "Show help message and exit")
 	directory := flag.String("directory", "", "The directory to run commity in")
+	split := flag.Bool("split", false, "Split the staged changes into several commits")
+	resume := flag.Bool("resume", false, "Restore the last draft of the repository without asking")
 	paramMap := ParamMap{}
 	flag.Var(&paramMap, "map", "Set default values for the form (e.g., -map key1=value1 -map key2=value2)")
 
@@ -408,26 +762,24 @@ func main() {
 	}
 	if *help {
 		fmt.Println("Usage: commity [options]")
+		fmt.Println("       commity <command> [arguments]")
+		fmt.Println("Commands:")
+		fmt.Println("  validate [file]  Check the configuration file for problems")
+		fmt.Println("  schema           Print the JSON Schema of the configuration file")
+		fmt.Println("  config migrate   Upgrade the configuration file to the newest format")
+		fmt.Println("  fixup            Commit the staged changes as fixup!, squash! or amend! commit")
+		fmt.Println("  revert <rev>     Revert a commit with a prefilled revert message")
+		fmt.Println("  cache <command>  List, show, clear or prune the cached values")
 		fmt.Println("Options:")
 		flag.PrintDefaults()
 		return
 	}
 
 	// handle the project directory
-	var dir = *directory
-	var err error
-	if dir == "" {
-		dir, err = os.Getwd()
-		if err != nil {
-			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error getting current directory: %v", err)))
-			os.Exit(1)
-		}
-	}
-	abs_dir, err := filepath.Abs(dir)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("failed to get absolute path: %v", err)))
-		os.Exit(1)
+	if *split {
+		runSplit(resolveDirectory(*directory), paramMap)
+		return
 	}
-	runCommity(abs_dir, paramMap)
+	runCommity(resolveDirectory(*directory), paramMap, commitOptions{resume: *resume, resumeCmd: "commity -resume"})
 
 }
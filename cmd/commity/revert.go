@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/michaelrampl/commity/internal/utils"
+)
+
+// runRevert implements the revert command.
+// It reverts the given commit in the working tree and shows the regular form prefilled with the revert values
+// of the configuration (e.g. type revert and a body referencing the reverted commit).
+// If no commit is created, e.g. because the form is canceled, the revert is undone.
+func runRevert(args []string) {
+	flags := flag.NewFlagSet("revert", flag.ExitOnError)
+	directory := flags.String("directory", "", "The directory to run commity in")
+	paramMap := ParamMap{}
+	flags.Var(&paramMap, "map", "Set default values for the form, overriding the revert values (e.g., -map key1=value1)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity revert [options] <rev>")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	dir := resolveDirectory(*directory)
+	repoPath, err := utils.FindGitRepository(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error findig git repository: %v", err)))
+		os.Exit(1)
+	}
+
+	// Load the configuration before the working tree is touched
+	cfg, _, err := utils.LoadConfig(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error loading configuration: %v", err)))
+		os.Exit(1)
+	}
+	// The revert values may be overridden for the current branch like the entries of the form
+	branch, err := utils.GetCurrentBranch(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading current branch: %v", err)))
+		os.Exit(1)
+	}
+	if err := cfg.ApplyBranch(branch); err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error applying branch overrides: %v", err)))
+		os.Exit(1)
+	}
+
+	commit, err := utils.RevertCommit(repoPath, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reverting commit: %v", err)))
+		os.Exit(1)
+	}
+	// Without a commit the working tree and index are restored, so the revert doesn't linger
+	onExit(func() {
+		if err := utils.UndoRevert(repoPath, commit); err != nil {
+			fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error undoing the revert: %v", err)))
+			return
+		}
+		fmt.Fprintln(os.Stderr, style_warning.Render("The revert was undone"))
+	})
+
+	subject, body, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	values, err := cfg.RevertValues(map[string]string{
+		"hash":      commit.Hash.String(),
+		"shortHash": commit.Hash.String()[:7],
+		"subject":   subject,
+		"body":      strings.TrimSpace(body),
+		"author":    commit.Author.Name,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error preparing revert values: %v", err)))
+		exit(1)
+	}
+	// The revert values are specific to this commit, so they are not stored like entered values
+	prefilled := make(map[string]bool)
+	for name, value := range values {
+		if _, exists := paramMap[name]; !exists {
+			paramMap[name] = value
+			prefilled[name] = true
+		}
+	}
+
+	// Unstaging files would commit a partial revert, so guard violations block it
+	runCommity(dir, paramMap, commitOptions{skipDraft: true, keepStaged: true, prefilled: prefilled})
+	if err := utils.FinishRevert(repoPath); err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: %v", err)))
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+// runSchema implements the schema command.
+// It prints the JSON Schema of the configuration file to stdout.
+func runSchema(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: commity schema")
+		os.Exit(1)
+	}
+
+	schema, err := config.Schema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error generating schema: %v", err)))
+		os.Exit(1)
+	}
+	fmt.Println(string(schema))
+}
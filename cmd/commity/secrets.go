@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/michaelrampl/commity/internal/config"
+	"github.com/michaelrampl/commity/internal/utils"
+)
+
+// maxListedSecrets is the number of secret findings listed in the form.
+const maxListedSecrets = 20
+
+// secretOverrideTrailer is the trailer recording why a commit was made despite secret findings.
+const secretOverrideTrailer = "Secret-Scan-Override"
+
+// checkSecrets scans the staged changes for secrets. If any are found, they are listed and the user
+// has to confirm the commit and enter a reason, otherwise commity exits.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - scan: The configuration of the scan.
+// - skip: Paths of files that are not scanned, e.g. because they cannot be committed anyway.
+//
+// Returns:
+// - The reason for committing despite the findings, or an empty string if nothing was found.
+func checkSecrets(repoPath string, scan *config.SecretScan, skip []string) string {
+	findings, err := utils.ScanStagedSecrets(repoPath, scan, skip)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error scanning staged changes for secrets: %v", err)))
+		exit(1)
+	}
+	if len(findings) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, finding := range findings {
+		if i == maxListedSecrets {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(findings)-maxListedSecrets))
+			break
+		}
+		lines = append(lines, finding.String())
+	}
+
+	var override bool
+	var reason string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewNote().
+				Title("Possible Secrets Found").
+				Description(strings.Join(lines, "\n")),
+			huh.NewConfirm().
+				Title("Commit anyway?").
+				Description("The reason is recorded in the commit message").
+				Affirmative("Yes").
+				Negative("No").
+				Value(&override),
+		),
+		huh.NewGroup(huh.NewInput().
+			Title("Reason").
+			Description("Why is it safe to commit these findings?").
+			Value(&reason).
+			Validate(func(input string) error {
+				if strings.TrimSpace(input) == "" {
+					return errors.New("A reason is required to commit anyway")
+				}
+				return nil
+			}),
+		).WithHideFunc(func() bool { return !override }),
+	).WithTheme(getTheme())
+
+	if err := form.Run(); err != nil {
+		if err == huh.ErrUserAborted {
+			fmt.Println(style_warning.Render("Commit Canceled - Goodbye!"))
+			exit(1)
+		}
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
+		exit(1)
+	}
+
+	if !override {
+		fmt.Fprintln(os.Stderr, style_error.Render("Commit blocked: remove the secrets from the staged changes"))
+		for _, finding := range findings {
+			fmt.Fprintln(os.Stderr, finding.String())
+		}
+		exit(1)
+	}
+	return strings.TrimSpace(reason)
+}
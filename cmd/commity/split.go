@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/michaelrampl/commity/internal/utils"
+)
+
+// containerDirs lists directories that usually contain one subdirectory per scope (e.g. internal/config).
+var containerDirs = []string{"apps", "cmd", "internal", "lib", "libs", "packages", "pkg", "services", "src"}
+
+// rootScope is the scope of files in the repository root.
+const rootScope = "(root)"
+
+// changeScope detects the scope of a changed file from its path: the top-level directory,
+// or the first two directories for container directories like internal or packages.
+func changeScope(path string) string {
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1:
+		return rootScope
+	case len(parts) > 2 && contains(containerDirs, parts[0]):
+		return parts[0] + "/" + parts[1]
+	default:
+		return parts[0]
+	}
+}
+
+// contains reports whether the list contains the given value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// unselectedPaths returns the paths of all staged changes that are not in the given list.
+func unselectedPaths(repoPath string, paths []string) []string {
+	changes, err := utils.GetStagedChanges(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking added files: %v", err)))
+		exit(1)
+	}
+	var unselected []string
+	for _, change := range changes {
+		if !contains(paths, change.Path) {
+			unselected = append(unselected, change.Path)
+		}
+	}
+	return unselected
+}
+
+// Split actions offered for the selected scopes.
+const (
+	splitCommit  = "commit"
+	splitUnstage = "unstage"
+	splitStop    = "stop"
+)
+
+// runSplit splits the staged changes into several commits. The changed files are grouped by scope,
+// and for every commit the user selects the scopes to include before the regular form is shown.
+// Selected scopes can also be unstaged instead of committed.
+func runSplit(directory string, paramMap ParamMap) {
+	repoPath, err := utils.FindGitRepository(directory)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error findig git repository: %v", err)))
+		os.Exit(1)
+	}
+
+	changes, err := utils.GetStagedChanges(repoPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error checking added files: %v", err)))
+		os.Exit(1)
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Nothing to commit in %v", repoPath)))
+		os.Exit(1)
+	}
+
+	var scopes []string
+	files := make(map[string][]string)
+	for _, change := range changes {
+		scope := changeScope(change.Path)
+		if files[scope] == nil {
+			scopes = append(scopes, scope)
+		}
+		files[scope] = append(files[scope], change.Path)
+	}
+
+	// A canceled or failed commit ends the split, report the files committed and what is still staged
+	var committed []string
+	onExit(func() {
+		if len(committed) > 0 {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Committed: %s", strings.Join(committed, ", "))))
+		}
+		for _, scope := range scopes {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Not committed, still staged: %s (%s)", scope, strings.Join(files[scope], ", "))))
+		}
+	})
+
+	for commit := 1; len(scopes) > 0; commit++ {
+		var selected []string
+		action := splitCommit
+		if len(scopes) == 1 {
+			selected = scopes
+		} else {
+			var options []huh.Option[string]
+			for i, scope := range scopes {
+				label := fmt.Sprintf("%s (%d files: %s)", scope, len(files[scope]), strings.Join(files[scope], ", "))
+				options = append(options, huh.NewOption(label, scope).Selected(i == 0))
+			}
+			form := huh.NewForm(huh.NewGroup(
+				huh.NewMultiSelect[string]().
+					Title(fmt.Sprintf("Commit %d", commit)).
+					Description("Select the staged changes to handle next, the others remain staged").
+					Options(options...).
+					Value(&selected).
+					Validate(func(selected []string) error {
+						if len(selected) == 0 {
+							return errors.New("Select at least one group")
+						}
+						return nil
+					}),
+				huh.NewSelect[string]().
+					Title("Action").
+					Options(
+						huh.NewOption("Commit the selected changes", splitCommit),
+						huh.NewOption("Unstage the selected changes", splitUnstage),
+						huh.NewOption("Stop and keep all remaining changes staged", splitStop),
+					).
+					Value(&action),
+			)).WithTheme(getTheme())
+
+			if err := form.Run(); err != nil {
+				if err == huh.ErrUserAborted {
+					fmt.Println(style_warning.Render("Split Canceled"))
+					exit(1)
+				}
+				fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error running commity: %v", err)))
+				exit(1)
+			}
+		}
+
+		var paths []string
+		for _, scope := range selected {
+			paths = append(paths, files[scope]...)
+		}
+
+		switch action {
+		case splitStop:
+			return
+		case splitUnstage:
+			if err := utils.UnstageFiles(repoPath, paths); err != nil {
+				fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error unstaging files: %v", err)))
+				exit(1)
+			}
+			commit--
+		default:
+			// Every group starts from the command line values, restored and draft values are filled in per group
+			// Files unstaged because of the guards are not committed
+			paths = runCommity(directory, maps.Clone(paramMap), commitOptions{paths: paths, resumeCmd: "commity -split"})
+			committed = append(committed, paths...)
+		}
+
+		var remaining []string
+		for _, scope := range scopes {
+			if !contains(selected, scope) {
+				remaining = append(remaining, scope)
+			}
+		}
+		scopes = remaining
+	}
+}
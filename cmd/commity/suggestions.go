@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/michaelrampl/commity/internal/config"
+	"github.com/michaelrampl/commity/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultCommitLimit is the number of recent commits scanned for suggestions if no limit is configured.
+const defaultCommitLimit = 100
+
+// collectSuggestions gathers the autocomplete suggestions for a text entry.
+// Previously entered values come first (most recent first), followed by the static values
+// and the values extracted from the subjects of recent commits. Duplicates are removed.
+func collectSuggestions(e *config.TextEntry, history []string, repoPath string) []string {
+	var suggestions []string
+	seen := make(map[string]bool)
+	add := func(value string) {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			suggestions = append(suggestions, value)
+		}
+	}
+
+	if e.Suggestions.History > 0 {
+		for _, value := range history {
+			add(value)
+		}
+	}
+	for _, value := range e.Suggestions.Values {
+		add(value)
+	}
+
+	if e.Suggestions.CommitPattern != "" {
+		re, err := regexp.Compile(e.Suggestions.CommitPattern)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: invalid commitPattern for %s: %v", e.Name, err)))
+			return suggestions
+		}
+		limit := e.Suggestions.CommitLimit
+		if limit <= 0 {
+			limit = defaultCommitLimit
+		}
+		commits, err := utils.GetRecentCommits(repoPath, limit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to read commit history: %v", err)))
+			return suggestions
+		}
+		for _, commit := range commits {
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			match := re.FindStringSubmatch(subject)
+			switch {
+			case match == nil:
+				continue
+			case len(match) > 1:
+				// Use the first capture group if the pattern has one
+				add(strings.TrimSpace(match[1]))
+			default:
+				add(strings.TrimSpace(match[0]))
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// loadSuggestionHistory loads the previously entered values of all entries from the history file
+// of the repository with the given identity (see cacheIdentity).
+func loadSuggestionHistory(identity string) (map[string][]string, error) {
+	historyFile, err := getRepoDataFilePath("suggestions", identity)
+	if err != nil {
+		return map[string][]string{}, err
+	}
+	data, err := os.ReadFile(historyFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// No history yet; return an empty map.
+			return map[string][]string{}, nil
+		}
+		return map[string][]string{}, err
+	}
+	history := make(map[string][]string)
+	if err := yaml.Unmarshal(data, &history); err != nil {
+		return map[string][]string{}, err
+	}
+	return history, nil
+}
+
+// historyValues returns the values to record in the suggestion history: the current value of every
+// single-line text entry with a history size, by entry name.
+func historyValues(entries []config.Entry) map[string]string {
+	values := make(map[string]string)
+	for _, entry := range entries {
+		e, ok := entry.(*config.TextEntry)
+		if !ok || e.Suggestions.History <= 0 || e.MultiLine {
+			continue
+		}
+		if current := e.ApplyTransforms(e.Value); current != "" {
+			values[e.Name] = current
+		}
+	}
+	return values
+}
+
+// updateSuggestionHistory records the values of all text entries with a history size,
+// keeping the most recent values first, and saves the history file. The history is read and written
+// while holding the cache lock, so values recorded by concurrent runs in the meantime are kept.
+// Nothing is read or locked if no entry keeps a history.
+func updateSuggestionHistory(entries *[]config.Entry, identity string) {
+	current := historyValues(*entries)
+	if len(current) == 0 {
+		return
+	}
+
+	unlock, err := lockCache()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save suggestion history: %v", err)))
+		return
+	}
+	defer unlock()
+
+	history, err := loadSuggestionHistory(identity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to load suggestion history: %v", err)))
+		return
+	}
+
+	for _, entry := range *entries {
+		e, ok := entry.(*config.TextEntry)
+		if !ok || current[e.Name] == "" {
+			continue
+		}
+		values := []string{current[e.Name]}
+		for _, value := range history[e.Name] {
+			if value != current[e.Name] && len(values) < e.Suggestions.History {
+				values = append(values, value)
+			}
+		}
+		history[e.Name] = values
+	}
+
+	if err := writeRepoDataFile("suggestions", identity, history); err != nil {
+		fmt.Fprintln(os.Stderr, style_warning.Render(fmt.Sprintf("Warning: failed to save suggestion history: %v", err)))
+	}
+}
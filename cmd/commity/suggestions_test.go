@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+// initRepo creates a repository in a temporary directory with one empty commit per message, the last one being HEAD.
+func initRepo(t *testing.T, messages ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, message := range messages {
+		signature := &object.Signature{Name: "Test", Email: "test@example.com", When: when.Add(time.Duration(i) * time.Minute)}
+		if _, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature, AllowEmptyCommits: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// useDataDir points the data directory of commity to a temporary directory.
+func useDataDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func TestCollectSuggestions(t *testing.T) {
+	repoPath := initRepo(t, "feat(api): add login", "fix(ui): align button", "docs: update readme", "fix(api): handle timeout")
+
+	tests := []struct {
+		name        string
+		suggestions config.Suggestions
+		history     []string
+		want        []string
+	}{
+		{
+			name:        "static values",
+			suggestions: config.Suggestions{Values: []string{"api", "ui", "api", ""}},
+			want:        []string{"api", "ui"},
+		},
+		{
+			name:        "history first",
+			suggestions: config.Suggestions{Values: []string{"api", "ui"}, History: 5},
+			history:     []string{"ui", "db"},
+			want:        []string{"ui", "db", "api"},
+		},
+		{
+			name:        "history disabled",
+			suggestions: config.Suggestions{Values: []string{"api"}},
+			history:     []string{"db"},
+			want:        []string{"api"},
+		},
+		{
+			name:        "commit capture group, most recent first",
+			suggestions: config.Suggestions{CommitPattern: `^\w+\((\w+)\)`},
+			want:        []string{"api", "ui"},
+		},
+		{
+			name:        "commit match without group",
+			suggestions: config.Suggestions{CommitPattern: `^docs`},
+			want:        []string{"docs"},
+		},
+		{
+			name:        "commit limit",
+			suggestions: config.Suggestions{CommitPattern: `^\w+\((\w+)\)`, CommitLimit: 1},
+			want:        []string{"api"},
+		},
+		{
+			name:        "invalid commit pattern",
+			suggestions: config.Suggestions{Values: []string{"api"}, CommitPattern: `(`},
+			want:        []string{"api"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry := &config.TextEntry{Name: "scope", Suggestions: test.suggestions}
+			if got := collectSuggestions(entry, test.history, repoPath); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("collectSuggestions() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateSuggestionHistory(t *testing.T) {
+	useDataDir(t)
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	if err := os.Mkdir(repoPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeRepoDataFile("suggestions", repoPath, map[string][]string{"scope": {"ui", "api", "db"}}); err != nil {
+		t.Fatal(err)
+	}
+	entries := []config.Entry{
+		&config.TextEntry{Name: "scope", Value: "api", Suggestions: config.Suggestions{History: 3}},
+		&config.TextEntry{Name: "subject", Value: "add login"},
+	}
+	updateSuggestionHistory(&entries, repoPath)
+
+	loaded, err := loadSuggestionHistory(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string][]string{"scope": {"api", "ui", "db"}}; !reflect.DeepEqual(loaded, want) {
+		t.Errorf("loadSuggestionHistory() = %v, want %v", loaded, want)
+	}
+}
+
+func TestUpdateSuggestionHistoryWithoutHistory(t *testing.T) {
+	useDataDir(t)
+	repoPath := t.TempDir()
+
+	// Entries without a history must neither wait for the cache lock nor write the history file
+	unlock, err := lockCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	entries := []config.Entry{
+		&config.TextEntry{Name: "scope", Value: "api", Suggestions: config.Suggestions{Values: []string{"api"}}},
+		&config.TextEntry{Name: "body", Value: "details", MultiLine: true, Suggestions: config.Suggestions{History: 3}},
+	}
+	start := time.Now()
+	updateSuggestionHistory(&entries, repoPath)
+	if elapsed := time.Since(start); elapsed >= cacheLockTimeout {
+		t.Errorf("updateSuggestionHistory() waited %v for the cache lock", elapsed)
+	}
+
+	historyFile, err := getRepoDataFilePath("suggestions", repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(historyFile); !os.IsNotExist(err) {
+		t.Errorf("suggestion history written: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+// runValidate implements the validate command.
+// It checks the given configuration file, or the one commity would load for the directory,
+// prints every problem as file:line:column and exits with a non-zero status if any were found.
+func runValidate(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	directory := flags.String("directory", "", "The directory to look up the configuration for")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: commity validate [options] [file]")
+		fmt.Fprintln(flags.Output(), "Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	cfgPath := flags.Arg(0)
+	if cfgPath == "" {
+		cfgPath = locateConfig(*directory)
+	}
+
+	problems, err := config.Validate(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Error reading configuration: %v", err)))
+		os.Exit(1)
+	}
+
+	errorCount := 0
+	for _, problem := range problems {
+		if problem.Warning {
+			fmt.Fprintln(os.Stderr, style_warning.Render(problem.Format(cfgPath)))
+			continue
+		}
+		fmt.Fprintln(os.Stderr, style_error.Render(problem.Format(cfgPath)))
+		errorCount++
+	}
+	if errorCount > 0 {
+		fmt.Fprintln(os.Stderr, style_error.Render(fmt.Sprintf("Found %d problem(s) in %s", errorCount, cfgPath)))
+		os.Exit(1)
+	}
+
+	fmt.Println(style_success.Render(fmt.Sprintf("Configuration %s is valid", cfgPath)))
+}
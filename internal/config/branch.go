@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BranchOverride adjusts the configuration on branches whose name matches a glob pattern.
+// All matching overrides are applied in the order they are defined.
+type BranchOverride struct {
+	Match    string              `yaml:"match"`    // Glob pattern matched against the branch name (e.g. "release/*")
+	Entries  []Entry             `yaml:"entries"`  // Entries to add, replacing an existing entry with the same name
+	Hide     []string            `yaml:"hide"`     // Names of the entries not shown in the form, their default value is used
+	Choices  map[string][]string `yaml:"choices"`  // Restricts choice entries (by name) to the listed values
+	Template string              `yaml:"template"` // Replaces the template if not empty
+}
+
+// UnmarshalYAML handles the deserialization of a BranchOverride, decoding its entries based on their type field.
+func (o *BranchOverride) UnmarshalYAML(value *yaml.Node) error {
+	var raw struct {
+		Match    string              `yaml:"match"`
+		Entries  []yaml.Node         `yaml:"entries"`
+		Hide     []string            `yaml:"hide"`
+		Choices  map[string][]string `yaml:"choices"`
+		Template string              `yaml:"template"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	if _, err := path.Match(raw.Match, ""); err != nil {
+		return nodeErrorf(value, "invalid branch pattern %q: %v", raw.Match, err)
+	}
+
+	o.Match = raw.Match
+	o.Hide = raw.Hide
+	o.Choices = raw.Choices
+	o.Template = raw.Template
+	for i := range raw.Entries {
+		entry, err := decodeEntry(&raw.Entries[i])
+		if err != nil {
+			return err
+		}
+		o.Entries = append(o.Entries, entry)
+	}
+	return nil
+}
+
+// ApplyBranch applies all overrides matching the given branch to the configuration.
+// An empty branch (e.g. a detached HEAD) matches no override.
+//
+// Arguments:
+// - branch: The short name of the current branch.
+//
+// Returns:
+// - An error if an override references an entry or choice that does not exist.
+func (c *Configuration) ApplyBranch(branch string) error {
+	if branch == "" {
+		return nil
+	}
+
+	for _, override := range c.Branches {
+		matched, err := path.Match(override.Match, branch)
+		if err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", override.Match, err)
+		}
+		if !matched {
+			continue
+		}
+
+		for _, entry := range override.Entries {
+			if index := c.entryIndex(entry.GetName()); index >= 0 {
+				c.Entries[index] = entry
+			} else {
+				c.Entries = append(c.Entries, entry)
+			}
+		}
+
+		for _, name := range override.Hide {
+			if c.entryIndex(name) < 0 {
+				return fmt.Errorf("branch override %q hides unknown entry %q", override.Match, name)
+			}
+			if c.hidden == nil {
+				c.hidden = make(map[string]bool)
+			}
+			c.hidden[name] = true
+		}
+
+		for name, values := range override.Choices {
+			index := c.entryIndex(name)
+			if index < 0 {
+				return fmt.Errorf("branch override %q restricts unknown entry %q", override.Match, name)
+			}
+			choiceEntry, ok := c.Entries[index].(*ChoiceEntry)
+			if !ok {
+				return fmt.Errorf("branch override %q restricts entry %q which is not a choice", override.Match, name)
+			}
+			if err := choiceEntry.restrict(values); err != nil {
+				return fmt.Errorf("branch override %q: %w", override.Match, err)
+			}
+		}
+
+		if override.Template != "" {
+			c.Template = override.Template
+		}
+	}
+
+	return nil
+}
+
+// IsHidden reports whether the entry with the given name is hidden from the form.
+func (c *Configuration) IsHidden(name string) bool {
+	return c.hidden[name]
+}
+
+// entryIndex returns the index of the entry with the given name, or -1 if there is none.
+func (c *Configuration) entryIndex(name string) int {
+	for i, entry := range c.Entries {
+		if entry.GetName() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// restrict limits the choices to the given values, keeping their original order.
+// If the current value is no longer available, the first remaining choice is selected.
+func (e *ChoiceEntry) restrict(values []string) error {
+	allowed := make(map[string]bool)
+	for _, value := range values {
+		allowed[value] = true
+	}
+
+	var choices []Choice
+	for _, choice := range e.Choices {
+		if allowed[choice.Value] {
+			choices = append(choices, choice)
+			delete(allowed, choice.Value)
+		}
+	}
+	for value := range allowed {
+		return fmt.Errorf("entry %q has no choice %q", e.Name, value)
+	}
+
+	e.Choices = choices
+	if !contains(values, e.Value) && len(choices) > 0 {
+		e.Value = choices[0].Value
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// branchConfig is the configuration the branch override tests start from.
+const branchConfig = `entries:
+  - type: Choice
+    name: type
+    choices:
+      - value: feat
+      - value: fix
+      - value: docs
+    default: feat
+  - type: Text
+    name: scope
+template: "{{ .type }}: {{ .scope }}"
+branches:
+  - match: release/*
+    hide: [scope]
+    choices:
+      type: [fix]
+    template: "{{ .type }}"
+  - match: docs/*
+    entries:
+      - type: Choice
+        name: type
+        choices:
+          - value: docs
+      - type: Text
+        name: page
+`
+
+func TestApplyBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		entries  []string
+		hidden   []string
+		choices  []string
+		value    string
+		template string
+	}{
+		{
+			name:     "no match",
+			branch:   "main",
+			entries:  []string{"type", "scope"},
+			choices:  []string{"feat", "fix", "docs"},
+			value:    "feat",
+			template: "{{ .type }}: {{ .scope }}",
+		},
+		{
+			name:     "detached head",
+			branch:   "",
+			entries:  []string{"type", "scope"},
+			choices:  []string{"feat", "fix", "docs"},
+			value:    "feat",
+			template: "{{ .type }}: {{ .scope }}",
+		},
+		{
+			name:     "hide, restrict and replace the template",
+			branch:   "release/1.2",
+			entries:  []string{"type", "scope"},
+			hidden:   []string{"scope"},
+			choices:  []string{"fix"},
+			value:    "fix",
+			template: "{{ .type }}",
+		},
+		{
+			name:     "pattern does not cross slashes",
+			branch:   "release/1.2/hotfix",
+			entries:  []string{"type", "scope"},
+			choices:  []string{"feat", "fix", "docs"},
+			value:    "feat",
+			template: "{{ .type }}: {{ .scope }}",
+		},
+		{
+			name:     "replace and add entries",
+			branch:   "docs/readme",
+			entries:  []string{"type", "scope", "page"},
+			choices:  []string{"docs"},
+			template: "{{ .type }}: {{ .scope }}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := ParseConfigFile(writeConfig(t, branchConfig))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.ApplyBranch(test.branch); err != nil {
+				t.Fatalf("ApplyBranch() error = %v", err)
+			}
+
+			var entries, hidden []string
+			for _, entry := range cfg.Entries {
+				entries = append(entries, entry.GetName())
+				if cfg.IsHidden(entry.GetName()) {
+					hidden = append(hidden, entry.GetName())
+				}
+			}
+			if !reflect.DeepEqual(entries, test.entries) {
+				t.Errorf("entries = %v, want %v", entries, test.entries)
+			}
+			if !reflect.DeepEqual(hidden, test.hidden) {
+				t.Errorf("hidden = %v, want %v", hidden, test.hidden)
+			}
+
+			choiceEntry := cfg.Entries[0].(*ChoiceEntry)
+			var choices []string
+			for _, choice := range choiceEntry.Choices {
+				choices = append(choices, choice.Value)
+			}
+			if !reflect.DeepEqual(choices, test.choices) {
+				t.Errorf("choices = %v, want %v", choices, test.choices)
+			}
+			if choiceEntry.Value != test.value {
+				t.Errorf("value = %q, want %q", choiceEntry.Value, test.value)
+			}
+			if cfg.Template != test.template {
+				t.Errorf("template = %q, want %q", cfg.Template, test.template)
+			}
+		})
+	}
+}
+
+func TestApplyBranchErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		want     string
+	}{
+		{"hide unknown entry", "hide: [nope]", `branch override "*" hides unknown entry "nope"`},
+		{"restrict unknown entry", "choices: {nope: [a]}", `branch override "*" restricts unknown entry "nope"`},
+		{"restrict text entry", "choices: {scope: [a]}", `branch override "*" restricts entry "scope" which is not a choice`},
+		{"restrict to unknown choice", "choices: {type: [chore]}", `branch override "*": entry "type" has no choice "chore"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := `entries:
+  - type: Choice
+    name: type
+    choices:
+      - value: feat
+  - type: Text
+    name: scope
+template: "{{ .type }}"
+branches:
+  - match: "*"
+    ` + test.override + "\n"
+			cfg, err := ParseConfigFile(writeConfig(t, config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.ApplyBranch("main"); err == nil || err.Error() != test.want {
+				t.Errorf("ApplyBranch() error = %v, want %s", err, test.want)
+			}
+		})
+	}
+}
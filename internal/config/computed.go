@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ComputedEntry represents a value derived from other entries after the form is completed.
+// It is never shown to the user, but available to the template and to other computed entries.
+type ComputedEntry struct {
+	Name     string `yaml:"name"`     // The unique name of the entry
+	Template string `yaml:"template"` // A template string computing the value from the other entries
+	Value    string `yaml:"-"`        // Runtime value (not serialized to YAML)
+}
+
+// GetName returns the name of the computed entry.
+func (e *ComputedEntry) GetName() string {
+	return e.Name
+}
+
+// GetValue returns the computed value of the entry.
+func (e *ComputedEntry) GetValue() interface{} {
+	return e.Value
+}
+
+// ComputedOrder returns the computed entries of the configuration in evaluation order,
+// so that every entry comes after the computed entries its template references.
+//
+// Returns:
+// - The computed entries in evaluation order.
+// - An error if a template cannot be parsed or the computed entries reference each other in a cycle.
+func (c *Configuration) ComputedOrder() ([]*ComputedEntry, error) {
+	entries := make(map[string]*ComputedEntry)
+	deps := make(map[string][]string)
+	var names []string
+	for _, entry := range c.Entries {
+		e, ok := entry.(*ComputedEntry)
+		if !ok {
+			continue
+		}
+		tmpl, err := template.New(e.Name).Parse(e.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template of computed entry %q: %w", e.Name, err)
+		}
+		entries[e.Name] = e
+		names = append(names, e.Name)
+		deps[e.Name] = nil
+		for _, ref := range templateFieldRefs(tmpl.Tree.Root) {
+			deps[e.Name] = append(deps[e.Name], ref.name)
+		}
+	}
+
+	order, err := sortComputed(names, deps)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ComputedEntry, len(order))
+	for i, name := range order {
+		result[i] = entries[name]
+	}
+	return result, nil
+}
+
+// cycleError reports computed entries that reference each other in a cycle.
+type cycleError struct {
+	names []string // The entries forming the cycle, starting and ending with the same entry
+}
+
+// Error describes the cycle (e.g. "a -> b -> a").
+func (e *cycleError) Error() string {
+	return fmt.Sprintf("cycle between computed entries: %s", strings.Join(e.names, " -> "))
+}
+
+// sortComputed orders the computed entries topologically based on the names their templates reference.
+// References to names that are not computed entries are ignored.
+//
+// Arguments:
+// - names: The names of all computed entries in definition order.
+// - deps: The names referenced by each computed entry.
+//
+// Returns:
+// - The names in evaluation order, or an error describing the first cycle found.
+func sortComputed(names []string, deps map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var order, path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := 0
+			for i, step := range path {
+				if step == name {
+					start = i
+				}
+			}
+			return &cycleError{names: append(append([]string{}, path[start:]...), name)}
+		case done:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if _, computed := deps[dep]; computed {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputedOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "definition order without dependencies",
+			entries: []Entry{
+				&ComputedEntry{Name: "a", Template: "{{ .type }}"},
+				&TextEntry{Name: "type"},
+				&ComputedEntry{Name: "b", Template: "{{ .type }}"},
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "dependencies first",
+			entries: []Entry{
+				&ComputedEntry{Name: "header", Template: "{{ .prefix }}: {{ .subject }}"},
+				&ComputedEntry{Name: "prefix", Template: "{{ .type }}{{ .scoped }}"},
+				&ComputedEntry{Name: "scoped", Template: "({{ .scope }})"},
+			},
+			want: []string{"scoped", "prefix", "header"},
+		},
+		{
+			name: "references in conditions and variables",
+			entries: []Entry{
+				&ComputedEntry{Name: "a", Template: "{{ if .b }}{{ $.c }}{{ end }}"},
+				&ComputedEntry{Name: "b", Template: "b"},
+				&ComputedEntry{Name: "c", Template: "c"},
+			},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "cycle",
+			entries: []Entry{
+				&ComputedEntry{Name: "a", Template: "{{ .b }}"},
+				&ComputedEntry{Name: "b", Template: "{{ .c }}"},
+				&ComputedEntry{Name: "c", Template: "{{ .a }}"},
+			},
+			wantErr: "cycle between computed entries: a -> b -> c -> a",
+		},
+		{
+			name: "cycle not starting at the first entry",
+			entries: []Entry{
+				&ComputedEntry{Name: "a", Template: "{{ .b }}"},
+				&ComputedEntry{Name: "b", Template: "{{ .c }}"},
+				&ComputedEntry{Name: "c", Template: "{{ .b }}"},
+			},
+			wantErr: "cycle between computed entries: b -> c -> b",
+		},
+		{
+			name:    "self reference",
+			entries: []Entry{&ComputedEntry{Name: "a", Template: "{{ .a }}"}},
+			wantErr: "cycle between computed entries: a -> a",
+		},
+		{
+			name:    "invalid template",
+			entries: []Entry{&ComputedEntry{Name: "a", Template: "{{ .a"}},
+			wantErr: `failed to parse template of computed entry "a": template: a:1: unclosed action`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &Configuration{Entries: test.entries}
+			order, err := cfg.ComputedOrder()
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("ComputedOrder() error = %v, want %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ComputedOrder() error = %v", err)
+			}
+			var names []string
+			for _, entry := range order {
+				names = append(names, entry.Name)
+			}
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("ComputedOrder() = %v, want %v", names, test.want)
+			}
+		})
+	}
+}
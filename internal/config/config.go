@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -18,17 +21,33 @@ type Entry interface {
 // TextEntry represents a text input field in the configuration.
 // It supports optional constraints like minimum and maximum length and whether it allows multiple lines.
 type TextEntry struct {
-	Name        string `yaml:"name"`        // The unique name of the entry
-	Label       string `yaml:"label"`       // A user-friendly label for the entry
-	Description string `yaml:"description"` // A description of the entry
-	MinLength   int    `yaml:"minLength"`   // Minimum length of the text
-	MaxLength   int    `yaml:"maxLength"`   // Maximum length of the text
-	MultiLine   bool   `yaml:"multiLine"`   // Whether the text entry supports multiple lines
-	Pattern     string `yaml:"pattern"`     // A regular expression pattern to validate the text
-	PatternHint string `yaml:"patternHint"` // A hint to display when the pattern does not match
-	Default     string `yaml:"default"`     // Default value for the entry
-	Value       string `yaml:"-"`           // Runtime value (not serialized to YAML)
-	Store       bool   `yaml:"store"`       // Whether to store the for the next run
+	Name         string          `yaml:"name"`         // The unique name of the entry
+	Label        string          `yaml:"label"`        // A user-friendly label for the entry
+	Description  string          `yaml:"description"`  // A description of the entry
+	MinLength    int             `yaml:"minLength"`    // Minimum length of the text
+	MaxLength    int             `yaml:"maxLength"`    // Maximum length of the text
+	MultiLine    bool            `yaml:"multiLine"`    // Whether the text entry supports multiple lines
+	Pattern      string          `yaml:"pattern"`      // A regular expression pattern to validate the text
+	PatternHint  string          `yaml:"patternHint"`  // A hint to display when the pattern does not match
+	Default      string          `yaml:"default"`      // Default value for the entry
+	Value        string          `yaml:"-"`            // Runtime value (not serialized to YAML)
+	Store        bool            `yaml:"store"`        // Whether to store the for the next run
+	StoreScope   StoreScope      `yaml:"storeScope"`   // Where the stored value is kept: repository (default), branch, worktree or global
+	StoreTTL     Duration        `yaml:"storeTTL"`     // How long the stored value is restored, e.g. "7d" (0 = no expiry)
+	Suggestions  Suggestions     `yaml:"suggestions"`  // Autocomplete suggestions for single-line entries
+	Transform    []Transform     `yaml:"transform"`    // Normalizations applied in order before validation and rendering
+	DenyList     []string        `yaml:"denyList"`     // Words and phrases the value must not contain (case-insensitive)
+	DenyPatterns []Pattern       `yaml:"denyPatterns"` // Regular expressions the value must not match
+	SpellCheck   *SpellCheck     `yaml:"spellCheck"`   // Spell check of the value (optional)
+	Imperative   ImperativeCheck `yaml:"imperative"`   // Check that the value starts with a verb in the imperative mood
+}
+
+// Suggestions configures the autocomplete suggestions offered for a single-line text entry.
+type Suggestions struct {
+	Values        []string `yaml:"values"`        // A static list of suggestions
+	History       int      `yaml:"history"`       // Number of previously entered values to remember and suggest (0 = disabled)
+	CommitPattern string   `yaml:"commitPattern"` // Regular expression extracting suggestions from the subjects of recent commits
+	CommitLimit   int      `yaml:"commitLimit"`   // Number of recent commits to scan (default 100)
 }
 
 // GetName returns the name of the text entry.
@@ -36,22 +55,24 @@ func (e *TextEntry) GetName() string {
 	return e.Name
 }
 
-// GetValue returns the runtime value of the text entry.
+// GetValue returns the runtime value of the text entry with all transforms applied.
 func (e *TextEntry) GetValue() interface{} {
-	return e.Value
+	return e.ApplyTransforms(e.Value)
 }
 
 // ChoiceEntry represents a choice input field in the configuration.
 // It allows selecting one value from a predefined list of choices.
 type ChoiceEntry struct {
-	Name        string   `yaml:"name"`        // The unique name of the entry
-	Label       string   `yaml:"label"`       // A user-friendly label for the entry
-	Description string   `yaml:"description"` // A description of the entry
-	Choices     []Choice `yaml:"choices"`     // Available choices for the entry
-	Default     string   `yaml:"default"`     // Default selected choice
-	Value       string   `yaml:"-"`           // Runtime value (not serialized to YAML)
-	Store       bool     `yaml:"store"`       // Whether to store the for the next run
-	ShowValues  bool     `yaml:"showValues"`  // Whether to show the internal values of the choices
+	Name        string     `yaml:"name"`        // The unique name of the entry
+	Label       string     `yaml:"label"`       // A user-friendly label for the entry
+	Description string     `yaml:"description"` // A description of the entry
+	Choices     []Choice   `yaml:"choices"`     // Available choices for the entry
+	Default     string     `yaml:"default"`     // Default selected choice
+	Value       string     `yaml:"-"`           // Runtime value (not serialized to YAML)
+	Store       bool       `yaml:"store"`       // Whether to store the for the next run
+	StoreScope  StoreScope `yaml:"storeScope"`  // Where the stored value is kept: repository (default), branch, worktree or global
+	StoreTTL    Duration   `yaml:"storeTTL"`    // How long the stored value is restored, e.g. "7d" (0 = no expiry)
+	ShowValues  bool       `yaml:"showValues"`  // Whether to show the internal values of the choices
 }
 
 // GetName returns the name of the choice entry.
@@ -67,12 +88,14 @@ func (e *ChoiceEntry) GetValue() interface{} {
 // BooleanEntry represents a boolean input field in the configuration.
 // It allows toggling a true/false value.
 type BooleanEntry struct {
-	Name        string `yaml:"name"`        // The unique name of the entry
-	Label       string `yaml:"label"`       // A user-friendly label for the entry
-	Description string `yaml:"description"` // A description of the entry
-	Default     bool   `yaml:"default"`     // Default value for the entry
-	Value       bool   `yaml:"-"`           // Runtime value (not serialized to YAML)
-	Store       bool   `yaml:"store"`       // Whether to store the for the next run
+	Name        string     `yaml:"name"`        // The unique name of the entry
+	Label       string     `yaml:"label"`       // A user-friendly label for the entry
+	Description string     `yaml:"description"` // A description of the entry
+	Default     bool       `yaml:"default"`     // Default value for the entry
+	Value       bool       `yaml:"-"`           // Runtime value (not serialized to YAML)
+	Store       bool       `yaml:"store"`       // Whether to store the for the next run
+	StoreScope  StoreScope `yaml:"storeScope"`  // Where the stored value is kept: repository (default), branch, worktree or global
+	StoreTTL    Duration   `yaml:"storeTTL"`    // How long the stored value is restored, e.g. "7d" (0 = no expiry)
 }
 
 // GetName returns the name of the boolean entry.
@@ -85,6 +108,81 @@ func (e *BooleanEntry) GetValue() interface{} {
 	return e.Value
 }
 
+// NumberEntry represents a numeric input field in the configuration.
+// It supports an optional range, integer-only input and a step the value has to be a multiple of.
+type NumberEntry struct {
+	Name        string     `yaml:"name"`        // The unique name of the entry
+	Label       string     `yaml:"label"`       // A user-friendly label for the entry
+	Description string     `yaml:"description"` // A description of the entry
+	Min         *float64   `yaml:"min"`         // Minimum value (optional)
+	Max         *float64   `yaml:"max"`         // Maximum value (optional)
+	Integer     bool       `yaml:"integer"`     // Whether only whole numbers are accepted
+	Step        float64    `yaml:"step"`        // The value must be a multiple of step, counted from min (0 = no restriction)
+	Default     *float64   `yaml:"default"`     // Default value for the entry (optional)
+	Value       float64    `yaml:"-"`           // Runtime value (not serialized to YAML)
+	Store       bool       `yaml:"store"`       // Whether to store the for the next run
+	StoreScope  StoreScope `yaml:"storeScope"`  // Where the stored value is kept: repository (default), branch, worktree or global
+	StoreTTL    Duration   `yaml:"storeTTL"`    // How long the stored value is restored, e.g. "7d" (0 = no expiry)
+}
+
+// GetName returns the name of the number entry.
+func (e *NumberEntry) GetName() string {
+	return e.Name
+}
+
+// GetValue returns the runtime value of the number entry.
+// Integer entries return an int64, all others a float64, even for whole numbers. Templates compare values
+// only with literals of the same type, so `gt .count 3` requires an integer entry and other entries
+// are compared with float literals like `gt .hours 3.0`.
+func (e *NumberEntry) GetValue() interface{} {
+	if e.Integer {
+		return int64(math.Round(e.Value))
+	}
+	return e.Value
+}
+
+// FormatValue formats a value of the number entry as string, e.g. for the input field or the cache.
+func (e *NumberEntry) FormatValue(value float64) string {
+	if e.Integer {
+		return strconv.FormatInt(int64(math.Round(value)), 10)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// ParseValue parses the input of the number entry and checks it against the configured constraints.
+//
+// Arguments:
+// - input: The text entered by the user.
+//
+// Returns:
+// - The parsed value, or an error describing the violated constraint.
+func (e *NumberEntry) ParseValue(input string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("Input must be a number")
+	}
+	if e.Integer && value != math.Trunc(value) {
+		return 0, fmt.Errorf("Input must be a whole number")
+	}
+	if e.Min != nil && value < *e.Min {
+		return 0, fmt.Errorf("Input must be at least %s", e.FormatValue(*e.Min))
+	}
+	if e.Max != nil && value > *e.Max {
+		return 0, fmt.Errorf("Input must be at most %s", e.FormatValue(*e.Max))
+	}
+	if e.Step > 0 {
+		base := 0.0
+		if e.Min != nil {
+			base = *e.Min
+		}
+		steps := (value - base) / e.Step
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			return 0, fmt.Errorf("Input must be a multiple of %s", strconv.FormatFloat(e.Step, 'f', -1, 64))
+		}
+	}
+	return value, nil
+}
+
 // Choice represents a single selectable option for a ChoiceEntry.
 type Choice struct {
 	Value string `yaml:"value"` // The internal value of the choice
@@ -93,9 +191,19 @@ type Choice struct {
 
 // Configuration holds all the configuration entries and the template string for rendering outputs.
 type Configuration struct {
-	Entries  []Entry `yaml:"entries"`  // A list of entries in the configuration
-	Template string  `yaml:"template"` // A template string for rendering outputs
-	Overview bool    `yaml:"overview"` // Whether to show an overview at the beginning of the form
+	Version  int              `yaml:"version"`  // The version of the configuration format
+	Entries  []Entry          `yaml:"entries"`  // A list of entries in the configuration
+	Template string           `yaml:"template"` // A template string for rendering outputs
+	Overview bool             `yaml:"overview"` // Whether to show an overview at the beginning of the form
+	Strict   bool             `yaml:"strict"`   // Whether unknown fields are rejected (enabled unless set to false)
+	Branches []BranchOverride `yaml:"branches"` // Overrides applied on branches matching a pattern
+	Rules    []Rule           `yaml:"rules"`    // Validation rules spanning several entries
+	Secrets  *SecretScan      `yaml:"secrets"`  // Scan of the staged changes for secrets (optional)
+	Guards   Guards           `yaml:"guards"`   // Checks of the staged files
+	Revert   Revert           `yaml:"revert"`   // Values prefilled by the revert command
+	Cache    Cache            `yaml:"cache"`    // How stored values are kept
+	Warnings []string         `yaml:"-"`        // Deprecation warnings collected while loading (not serialized to YAML)
+	hidden   map[string]bool  // Names of the entries hidden from the form by a branch override
 }
 
 // UnmarshalYAML handles the deserialization of the Configuration structure.
@@ -103,91 +211,149 @@ type Configuration struct {
 func (c *Configuration) UnmarshalYAML(value *yaml.Node) error {
 	// Define a temporary struct to parse the YAML
 	var raw struct {
-		Entries  []yaml.Node `yaml:"entries"`
-		Template string      `yaml:"template"`
-		Overview bool        `yaml:"overview"`
+		Version  int              `yaml:"version"`
+		Entries  []yaml.Node      `yaml:"entries"`
+		Template string           `yaml:"template"`
+		Overview bool             `yaml:"overview"`
+		Strict   *bool            `yaml:"strict"`
+		Branches []BranchOverride `yaml:"branches"`
+		Rules    []Rule           `yaml:"rules"`
+		Secrets  *SecretScan      `yaml:"secrets"`
+		Guards   Guards           `yaml:"guards"`
+		Revert   Revert           `yaml:"revert"`
+		Cache    Cache            `yaml:"cache"`
 	}
 	if err := value.Decode(&raw); err != nil {
 		return err
 	}
 
+	c.Version = raw.Version
 	c.Template = raw.Template
 	c.Overview = raw.Overview
+	c.Strict = raw.Strict == nil || *raw.Strict
 
-	// Parse each entry dynamically based on its type field
-	for _, node := range raw.Entries {
-		var entryType struct {
-			Type string `yaml:"type"`
+	// Reject misspelled or unsupported fields before they are silently ignored
+	if c.Strict {
+		if problems := unknownFields(value, reflect.TypeOf(Configuration{}), "configuration"); len(problems) > 0 {
+			return problemsError("unknown fields in configuration (set strict: false to ignore them):", problems)
 		}
-		if err := node.Decode(&entryType); err != nil {
+	}
+
+	// Parse each entry dynamically based on its type field
+	for i := range raw.Entries {
+		entry, err := decodeEntry(&raw.Entries[i])
+		if err != nil {
 			return err
 		}
+		c.Entries = append(c.Entries, entry)
+	}
+	c.Branches = raw.Branches
+	c.Rules = raw.Rules
+	c.Secrets = raw.Secrets
+	c.Guards = raw.Guards
+	c.Revert = raw.Revert
+	c.Cache = raw.Cache
 
-		var entry Entry
-		switch entryType.Type {
-		case "Text":
-			var textEntry TextEntry
-			if err := node.Decode(&textEntry); err != nil {
-				return err
-			}
-			textEntry.Value = textEntry.Default
-			entry = &textEntry
-		case "Choice":
-			var choiceEntry ChoiceEntry
-			if err := node.Decode(&choiceEntry); err != nil {
-				return err
-			}
-			if choiceEntry.ShowValues {
-				maxValueLength := 0
-				for _, choice := range choiceEntry.Choices {
-					if len(choice.Value) > maxValueLength {
-						maxValueLength = len(choice.Value)
-					}
-				}
-				for i, choice := range choiceEntry.Choices {
-					choiceEntry.Choices[i].Label = fmt.Sprintf("%s%s %s", choice.Value, strings.Repeat(" ", maxValueLength-len(choice.Value)), choice.Label)
+	return nil
+}
+
+// decodeEntry decodes a single entry node into the entry type selected by its type field
+// and initializes the runtime value with the default.
+func decodeEntry(node *yaml.Node) (Entry, error) {
+	var entryType struct {
+		Type string `yaml:"type"`
+	}
+	if err := node.Decode(&entryType); err != nil {
+		return nil, err
+	}
+
+	switch entryType.Type {
+	case "Text":
+		var textEntry TextEntry
+		if err := node.Decode(&textEntry); err != nil {
+			return nil, err
+		}
+		textEntry.Value = textEntry.Default
+		return &textEntry, nil
+	case "Choice":
+		var choiceEntry ChoiceEntry
+		if err := node.Decode(&choiceEntry); err != nil {
+			return nil, err
+		}
+		if choiceEntry.ShowValues {
+			maxValueLength := 0
+			for _, choice := range choiceEntry.Choices {
+				if len(choice.Value) > maxValueLength {
+					maxValueLength = len(choice.Value)
 				}
 			}
-
-			choiceEntry.Value = choiceEntry.Default
-			entry = &choiceEntry
-		case "Boolean":
-			var booleanEntry BooleanEntry
-			if err := node.Decode(&booleanEntry); err != nil {
-				return err
+			for i, choice := range choiceEntry.Choices {
+				choiceEntry.Choices[i].Label = fmt.Sprintf("%s%s %s", choice.Value, strings.Repeat(" ", maxValueLength-len(choice.Value)), choice.Label)
 			}
-			booleanEntry.Value = booleanEntry.Default
-			entry = &booleanEntry
-		default:
-			return fmt.Errorf("unknown entry type: %s", entryType.Type)
 		}
 
-		c.Entries = append(c.Entries, entry)
+		choiceEntry.Value = choiceEntry.Default
+		return &choiceEntry, nil
+	case "Number":
+		var numberEntry NumberEntry
+		if err := node.Decode(&numberEntry); err != nil {
+			return nil, err
+		}
+		if numberEntry.Default != nil {
+			numberEntry.Value = *numberEntry.Default
+		}
+		return &numberEntry, nil
+	case "Computed":
+		var computedEntry ComputedEntry
+		if err := node.Decode(&computedEntry); err != nil {
+			return nil, err
+		}
+		return &computedEntry, nil
+	case "Boolean":
+		var booleanEntry BooleanEntry
+		if err := node.Decode(&booleanEntry); err != nil {
+			return nil, err
+		}
+		booleanEntry.Value = booleanEntry.Default
+		return &booleanEntry, nil
+	default:
+		return nil, fmt.Errorf("unknown entry type: %s", entryType.Type)
 	}
-
-	return nil
 }
 
-// ParseConfigFile reads a YAML configuration file from the specified path and parses it into a Configuration struct.
+// ParseConfigFile reads a configuration file from the specified path and parses it into a Configuration struct.
+// The file may be written in YAML, JSON or TOML, or be a shared project file with a commity section (see FindInDirectory).
+// Files using an older version of the configuration format are migrated on the fly,
+// the resulting deprecation warnings are collected in the Warnings field.
+// Variable references in entry fields are resolved afterwards, see Interpolate.
 //
 // Arguments:
-// - path: The file path to the YAML configuration file.
+// - path: The file path to the configuration file.
 //
 // Returns:
 // - A pointer to the Configuration struct if the file is successfully parsed.
 // - An error if the file cannot be read or if parsing fails.
 func ParseConfigFile(path string) (*Configuration, error) {
-	file, err := os.Open(path)
+	root, err := readConfigNode(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	var config Configuration
-	decoder := yaml.NewDecoder(file)
-	if err := decoder.Decode(&config); err != nil {
+	warnings, _, err := Migrate(root)
+	if err != nil {
 		return nil, err
 	}
+	if err := Interpolate(root, filepath.Dir(path)); err != nil {
+		return nil, withPath(err, path)
+	}
+
+	var config Configuration
+	if err := root.Decode(&config); err != nil {
+		return nil, withPath(withoutZeroLines(err), path)
+	}
+	for _, warning := range warnings {
+		config.Warnings = append(config.Warnings, warning.Format(path))
+	}
 
 	return &config, nil
 }
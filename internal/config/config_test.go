@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNumberParseValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		input   string
+		want    float64
+		wantErr string
+	}{
+		{name: "number", entry: "{}", input: " 2.5 ", want: 2.5},
+		{name: "negative", entry: "{}", input: "-3", want: -3},
+		{name: "not a number", entry: "{}", input: "two", wantErr: "Input must be a number"},
+		{name: "empty", entry: "{}", input: "", wantErr: "Input must be a number"},
+		{name: "infinity", entry: "{}", input: "Inf", wantErr: "Input must be a number"},
+		{name: "whole number", entry: "{integer: true}", input: "3", want: 3},
+		{name: "fraction for integer", entry: "{integer: true}", input: "3.5", wantErr: "Input must be a whole number"},
+		{name: "below min", entry: "{min: 1, max: 5}", input: "0", wantErr: "Input must be at least 1"},
+		{name: "above max", entry: "{min: 1, max: 5}", input: "5.5", wantErr: "Input must be at most 5"},
+		{name: "bounds are inclusive", entry: "{min: 1, max: 5}", input: "5", want: 5},
+		{name: "multiple of step", entry: "{step: 0.25}", input: "1.75", want: 1.75},
+		{name: "not a multiple of step", entry: "{step: 0.25}", input: "1.3", wantErr: "Input must be a multiple of 0.25"},
+		{name: "step counted from min", entry: "{min: 1, step: 2}", input: "5", want: 5},
+		{name: "step not counted from zero", entry: "{min: 1, step: 2}", input: "4", wantErr: "Input must be a multiple of 2"},
+		{name: "step with rounding error", entry: "{step: 0.1}", input: "0.3", want: 0.3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entry NumberEntry
+			if err := yaml.Unmarshal([]byte(test.entry), &entry); err != nil {
+				t.Fatal(err)
+			}
+			got, err := entry.ParseValue(test.input)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("ParseValue(%q) error = %v, want %s", test.input, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseValue(%q) error = %v", test.input, err)
+			}
+			if got != test.want {
+				t.Errorf("ParseValue(%q) = %v, want %v", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNumberValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     NumberEntry
+		value     float64
+		formatted string
+		want      interface{}
+	}{
+		{name: "float", value: 2.5, formatted: "2.5", want: 2.5},
+		{name: "whole float", value: 2, formatted: "2", want: 2.0},
+		{name: "integer", entry: NumberEntry{Integer: true}, value: 3, formatted: "3", want: int64(3)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.entry.Value = test.value
+			if got := test.entry.FormatValue(test.value); got != test.formatted {
+				t.Errorf("FormatValue(%v) = %q, want %q", test.value, got, test.formatted)
+			}
+			if got := test.entry.GetValue(); got != test.want {
+				t.Errorf("GetValue() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
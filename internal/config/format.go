@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileExtensions lists the supported extensions of dedicated configuration files.
+var fileExtensions = []string{".yaml", ".yml", ".json", ".toml"}
+
+// sharedFiles lists project files shared with other tools and the path of the commity section within them.
+var sharedFiles = []struct {
+	name    string   // The file name
+	section []string // The keys leading to the commity section
+}{
+	{name: "package.json", section: []string{"commity"}},
+	{name: "pyproject.toml", section: []string{"tool", "commity"}},
+}
+
+// FindInDirectory returns the configuration files present in a directory.
+// Dedicated files are named base plus one of the supported extensions (e.g. ".commity.toml"),
+// shared project files like package.json are only returned if they contain a commity section.
+//
+// Arguments:
+// - dir: The directory to search.
+// - base: The file name of dedicated configuration files without extension.
+// - shared: Whether shared project files are considered.
+//
+// Returns:
+// - The paths of all configuration files found in the directory.
+func FindInDirectory(dir string, base string, shared bool) []string {
+	var found []string
+	for _, ext := range fileExtensions {
+		candidate := filepath.Join(dir, base+ext)
+		if _, err := os.Stat(candidate); err == nil {
+			found = append(found, candidate)
+		}
+	}
+
+	if shared {
+		for _, file := range sharedFiles {
+			candidate := filepath.Join(dir, file.name)
+			if _, err := os.Stat(candidate); err != nil {
+				continue
+			}
+			// Files that cannot be parsed or have no section belong to other tools only
+			if _, err := readConfigNode(candidate); err == nil {
+				found = append(found, candidate)
+			}
+		}
+	}
+
+	return found
+}
+
+// IsYAML reports whether the configuration file at the given path is a YAML file.
+func IsYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// readConfigNode reads a configuration file in any of the supported formats and returns its root mapping node.
+// YAML and JSON are parsed directly, keeping the positions of all nodes. TOML is converted, so positions are not available.
+// For shared project files the node of the commity section is returned.
+func readConfigNode(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *yaml.Node
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		var value map[string]interface{}
+		if _, err := toml.Decode(string(data), &value); err != nil {
+			return nil, err
+		}
+		root = &yaml.Node{}
+		if err := root.Encode(value); err != nil {
+			return nil, err
+		}
+	default:
+		// JSON is a subset of YAML, so both are handled by the YAML parser
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			return nil, fmt.Errorf("configuration file is empty")
+		}
+		root = doc.Content[0]
+	}
+
+	for _, file := range sharedFiles {
+		if file.name != filepath.Base(path) {
+			continue
+		}
+		for _, key := range file.section {
+			_, section := mappingValue(root, key)
+			if section == nil {
+				return nil, fmt.Errorf("%s has no %s section", file.name, strings.Join(file.section, "."))
+			}
+			root = section
+		}
+	}
+
+	return root, nil
+}
+
+// withoutZeroLines removes the "line 0" positions the yaml package reports for nodes without position,
+// i.e. those converted from TOML, as they don't point to the line of the file.
+func withoutZeroLines(err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		for i, message := range typeErr.Errors {
+			typeErr.Errors[i] = strings.TrimPrefix(message, "line 0: ")
+		}
+	}
+	return err
+}
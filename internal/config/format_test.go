@@ -0,0 +1,230 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatConfigs holds the same configuration in every supported format, keyed by file name.
+var formatConfigs = map[string]string{
+	".commity.yaml": `entries:
+  - type: Choice
+    name: type
+    choices:
+      - value: feat
+        label: A new feature
+      - value: fix
+        label: A bug fix
+    store: true
+  - type: Text
+    name: subject
+    maxLength: 50
+  - type: Number
+    name: estimate
+    min: 0.5
+    max: 8
+    step: 0.5
+  - type: Boolean
+    name: breaking
+    default: false
+template: "{{ .type }}: {{ .subject }}"
+overview: true
+guards:
+  maxFileSize: 1MB
+`,
+	".commity.json": `{
+  "entries": [
+    {"type": "Choice", "name": "type", "choices": [{"value": "feat", "label": "A new feature"}, {"value": "fix", "label": "A bug fix"}], "store": true},
+    {"type": "Text", "name": "subject", "maxLength": 50},
+    {"type": "Number", "name": "estimate", "min": 0.5, "max": 8, "step": 0.5},
+    {"type": "Boolean", "name": "breaking", "default": false}
+  ],
+  "template": "{{ .type }}: {{ .subject }}",
+  "overview": true,
+  "guards": {"maxFileSize": "1MB"}
+}
+`,
+	".commity.toml": `template = "{{ .type }}: {{ .subject }}"
+overview = true
+
+[guards]
+maxFileSize = "1MB"
+
+[[entries]]
+type = "Choice"
+name = "type"
+store = true
+choices = [
+  { value = "feat", label = "A new feature" },
+  { value = "fix", label = "A bug fix" },
+]
+
+[[entries]]
+type = "Text"
+name = "subject"
+maxLength = 50
+
+[[entries]]
+type = "Number"
+name = "estimate"
+min = 0.5
+max = 8
+step = 0.5
+
+[[entries]]
+type = "Boolean"
+name = "breaking"
+default = false
+`,
+	"package.json": `{
+  "name": "app",
+  "version": "1.0.0",
+  "commity": {
+    "entries": [
+      {"type": "Choice", "name": "type", "choices": [{"value": "feat", "label": "A new feature"}, {"value": "fix", "label": "A bug fix"}], "store": true},
+      {"type": "Text", "name": "subject", "maxLength": 50},
+      {"type": "Number", "name": "estimate", "min": 0.5, "max": 8, "step": 0.5},
+      {"type": "Boolean", "name": "breaking", "default": false}
+    ],
+    "template": "{{ .type }}: {{ .subject }}",
+    "overview": true,
+    "guards": {"maxFileSize": "1MB"}
+  }
+}
+`,
+	"pyproject.toml": `[project]
+name = "app"
+
+[tool.commity]
+template = "{{ .type }}: {{ .subject }}"
+overview = true
+
+[tool.commity.guards]
+maxFileSize = "1MB"
+
+[[tool.commity.entries]]
+type = "Choice"
+name = "type"
+store = true
+choices = [
+  { value = "feat", label = "A new feature" },
+  { value = "fix", label = "A bug fix" },
+]
+
+[[tool.commity.entries]]
+type = "Text"
+name = "subject"
+maxLength = 50
+
+[[tool.commity.entries]]
+type = "Number"
+name = "estimate"
+min = 0.5
+max = 8
+step = 0.5
+
+[[tool.commity.entries]]
+type = "Boolean"
+name = "breaking"
+default = false
+`,
+}
+
+func TestParseConfigFileFormats(t *testing.T) {
+	want, err := ParseConfigFile(writeFile(t, t.TempDir(), ".commity.yaml", formatConfigs[".commity.yaml"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want.Entries) != 4 || want.Guards.MaxFileSize != 1<<20 {
+		t.Fatalf("ParseConfigFile() of the YAML file = %+v", want)
+	}
+
+	for _, name := range []string{".commity.json", ".commity.toml", "package.json", "pyproject.toml"} {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseConfigFile(writeFile(t, t.TempDir(), name, formatConfigs[name]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseConfigFile() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseConfigFileFormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown field in JSON", file: ".commity.json", content: `{"template": "x", "templte": "y"}`, wantErr: `.commity.json:1:19: unknown field "templte" in configuration`},
+		{name: "unknown field in TOML", file: ".commity.toml", content: "template = \"x\"\ntemplte = \"y\"\n", wantErr: `unknown field "templte" in configuration`},
+		{name: "invalid TOML", file: ".commity.toml", content: "template = \n", wantErr: "toml"},
+		{name: "wrong type in TOML", file: ".commity.toml", content: "template = \"x\"\noverview = 3\n", wantErr: "\n  cannot unmarshal !!int `3` into bool"},
+		{name: "invalid value in TOML", file: ".commity.toml", content: "[[entries]]\ntype = \"Text\"\nname = \"s\"\nstoreScope = \"team\"\n", wantErr: "invalid store scope \"team\""},
+		{name: "package.json without section", file: "package.json", content: `{"name": "app"}`, wantErr: "package.json has no commity section"},
+		{name: "pyproject.toml without section", file: "pyproject.toml", content: "[tool.black]\nline-length = 88\n", wantErr: "pyproject.toml has no tool.commity section"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseConfigFile(writeFile(t, t.TempDir(), test.file, test.content))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("ParseConfigFile() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindInDirectory(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []string
+		shared bool
+		want   []string
+	}{
+		{name: "dedicated files in order of their extension", files: []string{".commity.toml", ".commity.json", ".commity.yml", ".commity.yaml"}, want: []string{".commity.yaml", ".commity.yml", ".commity.json", ".commity.toml"}},
+		{name: "dedicated before shared files", files: []string{"package.json", "pyproject.toml", ".commity.yaml"}, shared: true, want: []string{".commity.yaml", "package.json", "pyproject.toml"}},
+		{name: "shared files ignored", files: []string{"package.json", "pyproject.toml"}},
+		{name: "shared files without section", files: []string{"package.json", "pyproject.toml", "other.json"}, shared: true},
+		{name: "none", shared: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range test.files {
+				content := formatConfigs[file]
+				if test.name == "shared files without section" {
+					content = `{"name": "app"}`
+					if strings.HasSuffix(file, ".toml") {
+						content = "[project]\nname = \"app\"\n"
+					}
+				}
+				writeFile(t, dir, file, content)
+			}
+			var want []string
+			for _, file := range test.want {
+				want = append(want, filepath.Join(dir, file))
+			}
+			if got := FindInDirectory(dir, ".commity", test.shared); !reflect.DeepEqual(got, want) {
+				t.Errorf("FindInDirectory() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// writeFile writes a file into the given directory and returns its path.
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated path matches a glob.
+// In addition to the syntax of path.Match, "**" matches any number of directories,
+// and globs without a slash (e.g. "*.env") match the file name in any directory.
+func MatchGlob(glob string, file string) bool {
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(file))
+		return ok
+	}
+	return matchSegments(strings.Split(glob, "/"), strings.Split(file, "/"))
+}
+
+// matchSegments matches the segments of a path against the segments of a glob.
+func matchSegments(glob []string, file []string) bool {
+	if len(glob) == 0 {
+		return len(file) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(file); i++ {
+			if matchSegments(glob[1:], file[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(file) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(glob[0], file[0]); !ok {
+		return false
+	}
+	return matchSegments(glob[1:], file[1:])
+}
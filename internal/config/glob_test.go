@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		file string
+		want bool
+	}{
+		{"*.env", ".env", true},
+		{"*.env", "config/prod.env", true},
+		{"dist/**", "dist/app.js", true},
+		{"dist/**", "dist/assets/app.js", true},
+		{"dist/**", "src/dist/app.js", false},
+		{"**/testdata/*", "internal/config/testdata/a.yaml", true},
+		{"internal/*.go", "internal/config/config.go", false},
+		{"go.mod", "tools/go.mod", true},
+		{"go.mod", "go.sum", false},
+		{"**", "any/file.txt", true},
+		{"docs/**/*.md", "docs/index.md", true},
+		{"docs/**/*.md", "docs/guide/setup/intro.md", true},
+		{"docs/**/*.md", "docs/guide/image.png", false},
+		{"src/*/main.go", "src/app/main.go", true},
+		{"src/*/main.go", "src/main.go", false},
+		{"[a-c].txt", "sub/b.txt", true},
+		{"[a-c].txt", "d.txt", false},
+	}
+
+	for _, test := range tests {
+		if got := MatchGlob(test.glob, test.file); got != test.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", test.glob, test.file, got, test.want)
+		}
+	}
+}
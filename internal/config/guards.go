@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Guards configures the checks of the staged files run before the form is shown.
+type Guards struct {
+	MaxFileSize    FileSize    `yaml:"maxFileSize"`    // Maximum size of a staged file (0 = no limit)
+	ForbiddenPaths []string    `yaml:"forbiddenPaths"` // Globs of paths that must not be committed (e.g. "*.env", "dist/**")
+	Companions     []Companion `yaml:"companions"`     // Files that must be committed together
+}
+
+// Companion requires a staged change of one file whenever another file is changed, e.g. go.sum for go.mod.
+type Companion struct {
+	When    string `yaml:"when"`    // Glob of the paths that trigger the requirement
+	Require string `yaml:"require"` // Glob of the paths of which at least one must be changed as well
+}
+
+// FileSize is a size in bytes. It is written either as a number of bytes or with a unit, e.g. "500KB" or "1MB".
+type FileSize int64
+
+// fileSizeUnits lists the supported units, larger units first.
+var fileSizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// UnmarshalYAML handles the deserialization of a FileSize.
+func (s *FileSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseFileSize(value.Value)
+	if err != nil {
+		return nodeErrorf(value, "%v", err)
+	}
+	*s = size
+	return nil
+}
+
+// jsonSchema describes a file size as either a number of bytes or a string with unit.
+func (FileSize) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "integer", "minimum": 0},
+			map[string]interface{}{"type": "string", "pattern": `^\s*[0-9]+(\.[0-9]+)?\s*([KMG]?B)?\s*$`},
+		},
+	}
+}
+
+// ParseFileSize parses a size like "1024", "500KB" or "1.5MB". Units are binary and case-insensitive.
+func ParseFileSize(input string) (FileSize, error) {
+	text := strings.ToUpper(strings.TrimSpace(input))
+	factor := int64(1)
+	for _, unit := range fileSizeUnits {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			text = strings.TrimSpace(number)
+			factor = unit.factor
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid file size %q (expected e.g. 500KB or 1MB)", input)
+	}
+	return FileSize(number * float64(factor)), nil
+}
+
+// String formats the size with the largest unit that fits, rounded to one decimal, e.g. "1.5MB".
+func (s FileSize) String() string {
+	for _, unit := range fileSizeUnits {
+		if int64(s) >= unit.factor {
+			value := strconv.FormatFloat(float64(s)/float64(unit.factor), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + unit.suffix
+		}
+	}
+	return "0B"
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    FileSize
+		text    string
+		wantErr bool
+	}{
+		{input: "1024", want: 1024, text: "1KB"},
+		{input: "500KB", want: 500 << 10, text: "500KB"},
+		{input: " 1.5 mb ", want: 3 << 19, text: "1.5MB"},
+		{input: "2GB", want: 2 << 30, text: "2GB"},
+		{input: "10B", want: 10, text: "10B"},
+		{input: "0", want: 0, text: "0B"},
+		{input: "huge", wantErr: true},
+		{input: "-1KB", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		size, err := ParseFileSize(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseFileSize(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if size != test.want {
+			t.Errorf("ParseFileSize(%q) = %d, want %d", test.input, size, test.want)
+		}
+		if size.String() != test.text {
+			t.Errorf("FileSize(%d).String() = %q, want %q", size, size.String(), test.text)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImperativeCheck configures the check that a text entry starts with a verb in the imperative mood,
+// e.g. "add" instead of "added" or "adds".
+// It is either written as a boolean or as a mapping, which enables the check.
+type ImperativeCheck struct {
+	Enabled  bool     `yaml:"-"`        // Whether the check is enabled
+	Severity string   `yaml:"severity"` // "error" (default) or "warning"
+	Allow    []string `yaml:"allow"`    // First words that are always accepted
+}
+
+//go:embed verbs.txt
+var verbsFile string
+
+// verbs is the set of base verb forms the first word is compared against.
+var verbs = parseVerbs(verbsFile)
+
+// irregularVerbs maps irregular past forms to their base form.
+var irregularVerbs = map[string]string{
+	"built":   "build",
+	"bound":   "bind",
+	"brought": "bring",
+	"caught":  "catch",
+	"chose":   "choose",
+	"dealt":   "deal",
+	"found":   "find",
+	"froze":   "freeze",
+	"got":     "get",
+	"hid":     "hide",
+	"kept":    "keep",
+	"made":    "make",
+	"ran":     "run",
+	"rewrote": "rewrite",
+	"sent":    "send",
+	"threw":   "throw",
+	"wrote":   "write",
+}
+
+// parseVerbs parses the bundled verb list.
+func parseVerbs(data string) map[string]bool {
+	result := make(map[string]bool)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			result[line] = true
+		}
+	}
+	return result
+}
+
+// UnmarshalYAML handles the deserialization of an ImperativeCheck, which is either a boolean or a mapping.
+func (c *ImperativeCheck) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&c.Enabled)
+	}
+
+	var raw struct {
+		Severity string   `yaml:"severity"`
+		Allow    []string `yaml:"allow"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	c.Enabled = true
+	c.Severity = raw.Severity
+	c.Allow = raw.Allow
+	return nil
+}
+
+// jsonSchema describes the check as either a boolean or a mapping.
+func (ImperativeCheck) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "boolean"},
+			map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"severity": map[string]interface{}{"enum": severities},
+					"allow":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+}
+
+// Check checks that the value starts with a verb in the imperative mood.
+// The check is a heuristic: only first words that are recognized as a non-imperative form of a known verb are reported.
+//
+// Arguments:
+// - value: The value to check.
+//
+// Returns:
+// - A finding if the first word is not in the imperative mood, nil otherwise.
+func (c *ImperativeCheck) Check(value string) *Finding {
+	if !c.Enabled {
+		return nil
+	}
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	word := strings.ToLower(strings.Trim(fields[0], ".,:;!?\"'`()[]"))
+	for _, allowed := range c.Allow {
+		if strings.ToLower(allowed) == word {
+			return nil
+		}
+	}
+
+	base := imperativeForm(word)
+	if base == "" {
+		return nil
+	}
+	return &Finding{
+		Message: fmt.Sprintf("Use the imperative mood: %q instead of %q", base, word),
+		Warning: c.Severity == "warning",
+	}
+}
+
+// imperativeForm returns the base form of a known verb if the word is another form of it (e.g. "add" for "added"),
+// or an empty string if the word is already a base form or not recognized.
+func imperativeForm(word string) string {
+	if verbs[word] {
+		return ""
+	}
+	if base, ok := irregularVerbs[word]; ok {
+		return base
+	}
+
+	for _, suffix := range []struct {
+		suffix      string
+		replacement string
+	}{
+		{"ies", "y"}, {"ied", "y"},
+		{"es", ""}, {"s", ""},
+		{"ed", ""}, {"d", ""}, {"ed", "e"},
+		{"ing", ""}, {"ing", "e"},
+	} {
+		stem, ok := strings.CutSuffix(word, suffix.suffix)
+		if !ok || stem == "" {
+			continue
+		}
+		candidate := stem + suffix.replacement
+		if verbs[candidate] {
+			return candidate
+		}
+		// Doubled final consonants, e.g. "stopped" or "running"
+		if n := len(stem); suffix.replacement == "" && n > 2 && stem[n-1] == stem[n-2] && verbs[stem[:n-1]] {
+			return stem[:n-1]
+		}
+	}
+	return ""
+}
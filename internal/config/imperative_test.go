@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestImperativeCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		check string
+		value string
+		want  string
+	}{
+		{name: "imperative", check: "true", value: "Add login page"},
+		{name: "past tense", check: "true", value: "Added login page", want: `Use the imperative mood: "add" instead of "added"`},
+		{name: "third person", check: "true", value: "adds login page", want: `Use the imperative mood: "add" instead of "adds"`},
+		{name: "es suffix", check: "true", value: "fixes crash", want: `Use the imperative mood: "fix" instead of "fixes"`},
+		{name: "silent e", check: "true", value: "updated docs", want: `Use the imperative mood: "update" instead of "updated"`},
+		{name: "ies suffix", check: "true", value: "applies patch", want: `Use the imperative mood: "apply" instead of "applies"`},
+		{name: "gerund with silent e", check: "true", value: "removing flag", want: `Use the imperative mood: "remove" instead of "removing"`},
+		{name: "doubled consonant", check: "true", value: "stopped worker", want: `Use the imperative mood: "stop" instead of "stopped"`},
+		{name: "doubled consonant gerund", check: "true", value: "running tests", want: `Use the imperative mood: "run" instead of "running"`},
+		{name: "irregular verb", check: "true", value: "built image", want: `Use the imperative mood: "build" instead of "built"`},
+		{name: "punctuation is trimmed", check: "true", value: "Bumped: version", want: `Use the imperative mood: "bump" instead of "bumped"`},
+		{name: "unknown word", check: "true", value: "readme tweaks"},
+		{name: "base form ending in s", check: "true", value: "use cache"},
+		{name: "empty value", check: "true", value: "  "},
+		{name: "disabled", check: "false", value: "added login page"},
+		{name: "allowed word", check: "{allow: [Fixed]}", value: "fixed-width layout"},
+		{name: "mapping enables the check", check: "{severity: error}", value: "added page", want: `Use the imperative mood: "add" instead of "added"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var check ImperativeCheck
+			if err := yaml.Unmarshal([]byte(test.check), &check); err != nil {
+				t.Fatal(err)
+			}
+			finding := check.Check(test.value)
+			switch {
+			case test.want == "" && finding != nil:
+				t.Errorf("Check(%q) = %q, want no finding", test.value, finding.Message)
+			case test.want != "" && finding == nil:
+				t.Errorf("Check(%q) = nil, want %q", test.value, test.want)
+			case test.want != "" && finding.Message != test.want:
+				t.Errorf("Check(%q) = %q, want %q", test.value, finding.Message, test.want)
+			}
+		})
+	}
+}
+
+func TestImperativeCheckSeverity(t *testing.T) {
+	tests := []struct {
+		check   string
+		warning bool
+	}{
+		{"true", false},
+		{"{severity: error}", false},
+		{"{severity: warning}", true},
+	}
+
+	for _, test := range tests {
+		var check ImperativeCheck
+		if err := yaml.Unmarshal([]byte(test.check), &check); err != nil {
+			t.Fatal(err)
+		}
+		if finding := check.Check("added page"); finding == nil || finding.Warning != test.warning {
+			t.Errorf("Check() with %s = %v, want warning %v", test.check, finding, test.warning)
+		}
+	}
+}
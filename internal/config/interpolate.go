@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// interpolatedFields lists the fields of text and choice entries that support variable references.
+var interpolatedFields = []string{"label", "description", "default", "pattern", "patternHint"}
+
+// interpolatedChoiceFields lists the fields of the choices of a choice entry that support variable references.
+var interpolatedChoiceFields = []string{"value", "label"}
+
+// Interpolate replaces variable references in the string fields of text and choice entries.
+//
+// The following references are supported:
+// - ${NAME}: The value of the environment variable NAME, which must be defined.
+// - ${git:KEY}: The value of the git config KEY (e.g. ${git:user.email}), which must be set.
+// - ${NAME:-fallback}, ${git:KEY:-fallback}: The fallback is used if the value is undefined or empty.
+// - $${: An escaped, literal "${".
+//
+// Arguments:
+// - root: The root mapping node of the configuration document, modified in place.
+// - dir: The directory git config values are read from.
+//
+// Returns:
+// - An error listing every undefined variable or malformed reference.
+func Interpolate(root *yaml.Node, dir string) error {
+	if problems := interpolateNode(root, dir); len(problems) > 0 {
+		return problemsError("failed to resolve variables in configuration:", problems)
+	}
+	return nil
+}
+
+// interpolateNode replaces the variable references in the configuration document and reports every reference
+// that cannot be resolved.
+func interpolateNode(root *yaml.Node, dir string) []Problem {
+	resolver := &variableResolver{dir: dir, git: make(map[string]*string)}
+	var problems []Problem
+
+	interpolate := func(node *yaml.Node, keys []string) {
+		for _, key := range keys {
+			_, value := mappingValue(node, key)
+			if value == nil || value.Kind != yaml.ScalarNode || !strings.Contains(value.Value, "${") {
+				continue
+			}
+			result, err := resolver.expand(value.Value)
+			if err != nil {
+				problems = append(problems, Problem{Line: value.Line, Column: value.Column, Message: err.Error()})
+				continue
+			}
+			value.Value = result
+		}
+	}
+
+	for _, entries := range entryLists(root) {
+		for _, entry := range entries.Content {
+			_, typeNode := mappingValue(entry, "type")
+			if typeNode == nil || (typeNode.Value != "Text" && typeNode.Value != "Choice") {
+				continue
+			}
+			interpolate(entry, interpolatedFields)
+			if _, choices := mappingValue(entry, "choices"); choices != nil && choices.Kind == yaml.SequenceNode {
+				for _, choice := range choices.Content {
+					interpolate(choice, interpolatedChoiceFields)
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// entryLists returns the regular entry list and the entry lists of all branch overrides of the document.
+func entryLists(root *yaml.Node) []*yaml.Node {
+	var lists []*yaml.Node
+	if _, entries := mappingValue(root, "entries"); entries != nil && entries.Kind == yaml.SequenceNode {
+		lists = append(lists, entries)
+	}
+	if _, branches := mappingValue(root, "branches"); branches != nil && branches.Kind == yaml.SequenceNode {
+		for _, branch := range branches.Content {
+			if _, entries := mappingValue(branch, "entries"); entries != nil && entries.Kind == yaml.SequenceNode {
+				lists = append(lists, entries)
+			}
+		}
+	}
+	return lists
+}
+
+// variableResolver resolves variable references against the environment and the git config.
+type variableResolver struct {
+	dir string             // The directory git config values are read from
+	git map[string]*string // Cached git config values, nil if the key is not set
+}
+
+// expand replaces all variable references within the string.
+func (r *variableResolver) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			b.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference in %q", s)
+			}
+			value, err := r.resolve(s[i+2 : i+2+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += 2 + end + 1
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+// resolve returns the value of a single variable reference (without the surrounding "${" and "}").
+func (r *variableResolver) resolve(reference string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(reference, ":-")
+	if name == "" {
+		return "", fmt.Errorf("empty variable reference")
+	}
+
+	var value string
+	var defined bool
+	if key, isGit := strings.CutPrefix(name, "git:"); isGit {
+		var err error
+		value, defined, err = r.gitConfig(key)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		value, defined = os.LookupEnv(name)
+	}
+
+	if hasFallback && value == "" {
+		return fallback, nil
+	}
+	if !defined {
+		return "", fmt.Errorf("undefined variable %q", name)
+	}
+	return value, nil
+}
+
+// gitConfig reads a git config value by asking the git binary, so includeIf and similar settings are honored.
+func (r *variableResolver) gitConfig(key string) (string, bool, error) {
+	if value, ok := r.git[key]; ok {
+		if value == nil {
+			return "", false, nil
+		}
+		return *value, true, nil
+	}
+
+	cmd := exec.Command("git", "-C", r.dir, "config", "--get", key)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		// git exits with status 1 if the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			r.git[key] = nil
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("git config %s: %s", key, strings.TrimSpace(out.String()))
+	}
+
+	value := strings.TrimSpace(out.String())
+	r.git[key] = &value
+	return value, true, nil
+}
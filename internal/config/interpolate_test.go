@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("COMMITY_TEST_NAME", "ada")
+	t.Setenv("COMMITY_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "variable", value: "${COMMITY_TEST_NAME}", want: "ada"},
+		{name: "embedded variable", value: "Hi ${COMMITY_TEST_NAME}!", want: "Hi ada!"},
+		{name: "fallback for undefined", value: "${COMMITY_TEST_UNSET:-none}", want: "none"},
+		{name: "fallback for empty", value: "${COMMITY_TEST_EMPTY:-none}", want: "none"},
+		{name: "empty without fallback", value: "[${COMMITY_TEST_EMPTY}]", want: "[]"},
+		{name: "escaped reference", value: "$${COMMITY_TEST_NAME}", want: "${COMMITY_TEST_NAME}"},
+		{name: "git fallback", value: "${git:commity.test.unset:-none}", want: "none"},
+		{name: "undefined", value: "${COMMITY_TEST_UNSET}", wantErr: `4:12: undefined variable "COMMITY_TEST_UNSET"`},
+		{name: "unterminated", value: "${COMMITY_TEST_NAME", wantErr: `4:12: unterminated variable reference in "${COMMITY_TEST_NAME"`},
+		{name: "empty reference", value: "${}", wantErr: "4:12: empty variable reference"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := parseNode(t, fmt.Sprintf("entries:\n  - type: Text\n    name: s\n    label: %q\n", test.value))
+			problems := interpolateNode(root, t.TempDir())
+			if test.wantErr != "" {
+				if len(problems) != 1 || problems[0].String() != test.wantErr {
+					t.Errorf("interpolateNode() problems = %v, want %s", problems, test.wantErr)
+				}
+				return
+			}
+			if len(problems) > 0 {
+				t.Fatalf("interpolateNode() problems = %v", problems)
+			}
+			_, entries := mappingValue(root, "entries")
+			if _, label := mappingValue(entries.Content[0], "label"); label.Value != test.want {
+				t.Errorf("label = %q, want %q", label.Value, test.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateFields(t *testing.T) {
+	t.Setenv("COMMITY_TEST_NAME", "ada")
+
+	root := parseNode(t, `entries:
+  - type: Choice
+    name: owner
+    choices:
+      - value: ${COMMITY_TEST_NAME}
+        label: Owner ${COMMITY_TEST_NAME}
+  - type: Boolean
+    name: breaking
+    description: ${COMMITY_TEST_NAME}
+branches:
+  - match: main
+    entries:
+      - type: Text
+        name: reviewer
+        default: ${COMMITY_TEST_NAME}
+`)
+	if problems := interpolateNode(root, t.TempDir()); len(problems) > 0 {
+		t.Fatalf("interpolateNode() problems = %v", problems)
+	}
+
+	var cfg struct {
+		Entries []struct {
+			Choices     []Choice `yaml:"choices"`
+			Description string   `yaml:"description"`
+		} `yaml:"entries"`
+		Branches []struct {
+			Entries []struct {
+				Default string `yaml:"default"`
+			} `yaml:"entries"`
+		} `yaml:"branches"`
+	}
+	if err := root.Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Choice{{Value: "ada", Label: "Owner ada"}}; !reflect.DeepEqual(cfg.Entries[0].Choices, want) {
+		t.Errorf("choices = %v, want %v", cfg.Entries[0].Choices, want)
+	}
+	// Only text and choice entries are interpolated
+	if got := cfg.Entries[1].Description; got != "${COMMITY_TEST_NAME}" {
+		t.Errorf("boolean description = %q, want it unchanged", got)
+	}
+	if got := cfg.Branches[0].Entries[0].Default; got != "ada" {
+		t.Errorf("branch entry default = %q, want %q", got, "ada")
+	}
+}
+
+// parseNode parses a YAML document and returns its root node.
+func parseNode(t *testing.T, content string) *yaml.Node {
+	t.Helper()
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		t.Fatal(err)
+	}
+	return document.Content[0]
+}
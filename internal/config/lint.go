@@ -0,0 +1,230 @@
+package config
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Finding is a problem found in the value of an entry.
+type Finding struct {
+	Message string // The description of the problem
+	Warning bool   // Whether the finding is only a warning that does not block the commit
+}
+
+// SpellCheck configures the spell check of a text entry.
+type SpellCheck struct {
+	Severity   string   `yaml:"severity"`   // "error" or "warning" (default warning)
+	Dictionary string   `yaml:"dictionary"` // A word list, absolute or relative to the data directory (optional)
+	Ignore     []string `yaml:"ignore"`     // Words that are always accepted
+}
+
+// severities lists the valid severities of optional checks.
+var severities = []string{"error", "warning"}
+
+// Dictionary is a set of known, lowercase words.
+type Dictionary map[string]bool
+
+//go:embed misspellings.txt
+var misspellingsFile string
+
+// misspellings maps common misspellings to their correction.
+var misspellings = parseMisspellings(misspellingsFile)
+
+// parseMisspellings parses the bundled list of misspellings.
+func parseMisspellings(data string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if wrong, correct, ok := strings.Cut(line, " "); ok {
+			result[wrong] = correct
+		}
+	}
+	return result
+}
+
+// LoadDictionary reads a word list with one word per line. Empty lines and lines starting with # are ignored.
+//
+// Arguments:
+// - path: The path of the word list.
+//
+// Returns:
+// - The dictionary, or an error if the file cannot be read.
+func LoadDictionary(path string) (Dictionary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dict := make(Dictionary)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" && !strings.HasPrefix(word, "#") {
+			dict[strings.ToLower(word)] = true
+		}
+	}
+	return dict, scanner.Err()
+}
+
+// Pattern is a regular expression of the configuration. It is compiled once when the configuration is loaded,
+// so checking a value on every keystroke doesn't compile it again.
+type Pattern struct {
+	re *regexp.Regexp
+}
+
+// NewPattern compiles a regular expression into a Pattern.
+func NewPattern(expr string) (Pattern, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Pattern{}, err
+	}
+	return Pattern{re: re}, nil
+}
+
+// UnmarshalYAML handles the deserialization of a Pattern.
+func (p *Pattern) UnmarshalYAML(value *yaml.Node) error {
+	pattern, err := NewPattern(value.Value)
+	if err != nil {
+		return nodeErrorf(value, "invalid pattern: %v", err)
+	}
+	*p = pattern
+	return nil
+}
+
+// jsonSchema describes a pattern as string holding a regular expression.
+func (Pattern) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "string", "format": "regex"}
+}
+
+// String returns the source of the regular expression.
+func (p Pattern) String() string {
+	if p.re == nil {
+		return ""
+	}
+	return p.re.String()
+}
+
+// wordPattern matches the words checked by the spell check.
+var wordPattern = regexp.MustCompile(`[\p{L}']+`)
+
+// Lint checks a value of the text entry against its deny list, deny patterns, imperative mood check and spell check.
+//
+// Arguments:
+// - value: The value to check, with transforms already applied.
+// - dict: The dictionary of the spell check, nil if only the bundled list of misspellings is used.
+//
+// Returns:
+// - All findings; findings that are not warnings must block the commit.
+func (e *TextEntry) Lint(value string, dict Dictionary) []Finding {
+	var findings []Finding
+
+	for _, word := range e.DenyList {
+		if containsWord(value, word) {
+			findings = append(findings, Finding{Message: fmt.Sprintf("Input must not contain %q", word)})
+		}
+	}
+	for _, pattern := range e.DenyPatterns {
+		if pattern.re == nil {
+			continue
+		}
+		if match := pattern.re.FindString(value); match != "" {
+			findings = append(findings, Finding{Message: fmt.Sprintf("Input must not contain %q", match)})
+		}
+	}
+
+	if finding := e.Imperative.Check(value); finding != nil {
+		findings = append(findings, *finding)
+	}
+
+	if e.SpellCheck != nil {
+		warning := e.SpellCheck.Severity != "error"
+		for _, word := range e.SpellCheck.misspelledWords(value, dict) {
+			findings = append(findings, Finding{Message: word, Warning: warning})
+		}
+	}
+
+	return findings
+}
+
+// containsWord reports whether the value contains the word or phrase, ignoring case and only at word boundaries.
+func containsWord(value string, word string) bool {
+	if word == "" {
+		return false
+	}
+	value, word = strings.ToLower(value), strings.ToLower(word)
+	for offset := 0; offset < len(value); {
+		index := strings.Index(value[offset:], word)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(word)
+		before, _ := utf8.DecodeLastRuneInString(value[:start])
+		after, _ := utf8.DecodeRuneInString(value[end:])
+		if (start == 0 || !wordRune(before)) && (end == len(value) || !wordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(value[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// wordRune reports whether the rune is part of a word, i.e. a letter, digit or underscore.
+func wordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// misspelledWords returns a message for every misspelled word in the value.
+// Words with inner capitals (e.g. identifiers like "GetValue") and all-caps words are skipped.
+func (s *SpellCheck) misspelledWords(value string, dict Dictionary) []string {
+	var messages []string
+	seen := make(map[string]bool)
+	for _, word := range wordPattern.FindAllString(value, -1) {
+		word = strings.TrimSuffix(strings.Trim(word, "'"), "'s")
+		lower := strings.ToLower(word)
+		if lower == "" || seen[lower] || s.ignored(lower) || !plainWord(word) {
+			continue
+		}
+		seen[lower] = true
+
+		if correct, ok := misspellings[lower]; ok {
+			messages = append(messages, fmt.Sprintf("Possible typo %q (did you mean %q?)", word, correct))
+		} else if dict != nil && !dict[lower] {
+			messages = append(messages, fmt.Sprintf("Unknown word %q", word))
+		}
+	}
+	return messages
+}
+
+// ignored reports whether the lowercase word is on the ignore list of the spell check.
+func (s *SpellCheck) ignored(word string) bool {
+	for _, ignore := range s.Ignore {
+		if strings.ToLower(ignore) == word {
+			return true
+		}
+	}
+	return false
+}
+
+// plainWord reports whether the word is a regular word and not an identifier or abbreviation.
+func plainWord(word string) bool {
+	for i, r := range []rune(word) {
+		if i > 0 && unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
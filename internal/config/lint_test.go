@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dict := Dictionary{"add": true, "login": true, "page": true}
+
+	tests := []struct {
+		name  string
+		entry TextEntry
+		value string
+		dict  Dictionary
+		want  []Finding
+	}{
+		{
+			name:  "deny list ignores case",
+			entry: TextEntry{DenyList: []string{"wip", "do not merge"}},
+			value: "WIP: add login, Do Not Merge",
+			want: []Finding{
+				{Message: `Input must not contain "wip"`},
+				{Message: `Input must not contain "do not merge"`},
+			},
+		},
+		{
+			name:  "deny list matches whole words only",
+			entry: TextEntry{DenyList: []string{"wip"}},
+			value: "add wipe_wip and swipe",
+		},
+		{
+			name:  "deny pattern reports the match",
+			entry: TextEntry{DenyPatterns: mustPatterns(t, `(?i)fixup!?`, `JIRA-\d+`)},
+			value: "add login for JIRA-42",
+			want:  []Finding{{Message: `Input must not contain "JIRA-42"`}},
+		},
+		{
+			name:  "deny list matches phrases and other scripts",
+			entry: TextEntry{DenyList: []string{"do not merge", "straße", "tbd"}},
+			value: "Do not merge the STRASSE Straße change, tbd_later",
+			want: []Finding{
+				{Message: `Input must not contain "do not merge"`},
+				{Message: `Input must not contain "straße"`},
+			},
+		},
+		{
+			name:  "deny list matches a later occurrence",
+			entry: TextEntry{DenyList: []string{"wip"}},
+			value: "swip wip",
+			want:  []Finding{{Message: `Input must not contain "wip"`}},
+		},
+		{
+			name:  "bundled misspellings are warnings by default",
+			entry: TextEntry{SpellCheck: &SpellCheck{}},
+			value: "fix adress accross pages, Adress again",
+			want: []Finding{
+				{Message: `Possible typo "adress" (did you mean "address"?)`, Warning: true},
+				{Message: `Possible typo "accross" (did you mean "across"?)`, Warning: true},
+			},
+		},
+		{
+			name:  "error severity",
+			entry: TextEntry{SpellCheck: &SpellCheck{Severity: "error"}},
+			value: "fix adress",
+			want:  []Finding{{Message: `Possible typo "adress" (did you mean "address"?)`}},
+		},
+		{
+			name:  "ignored words, identifiers and abbreviations",
+			entry: TextEntry{SpellCheck: &SpellCheck{Ignore: []string{"Adress"}}},
+			value: "fix adress in getAdress and ACCROSS",
+		},
+		{
+			name:  "unknown words with dictionary",
+			entry: TextEntry{SpellCheck: &SpellCheck{Ignore: []string{"oauth"}}},
+			value: "add login's oauth pgae",
+			dict:  dict,
+			want:  []Finding{{Message: `Unknown word "pgae"`, Warning: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.entry.Lint(test.value, test.dict); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Lint(%q) = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# project words\nCommity\n\n  refactor \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dict, err := LoadDictionary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Dictionary{"commity": true, "refactor": true}); !reflect.DeepEqual(dict, want) {
+		t.Errorf("LoadDictionary() = %v, want %v", dict, want)
+	}
+}
+
+// mustPatterns compiles the regular expressions into patterns.
+func mustPatterns(t *testing.T, exprs ...string) []Pattern {
+	t.Helper()
+	patterns := make([]Pattern, len(exprs))
+	for i, expr := range exprs {
+		pattern, err := NewPattern(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		patterns[i] = pattern
+	}
+	return patterns
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the newest version of the configuration format.
+// Files without a version field are treated as version 1.
+const CurrentVersion = 1
+
+// migration upgrades a configuration document from one version to the next.
+// It modifies the document in place and returns a deprecation warning for every change it made.
+type migration struct {
+	from  int                             // The version the migration upgrades from
+	apply func(root *yaml.Node) []Problem // Upgrades the document to version from+1
+}
+
+// migrations lists all migrations in ascending order of their source version.
+// A change of the format adds a migration from the previous version and increments CurrentVersion.
+// Version 1 is the first version of the format, so there are no migrations yet and Migrate only sets the version field.
+var migrations = []migration{}
+
+// Migrate upgrades a configuration document to CurrentVersion and sets its version field accordingly.
+// The document is modified in place, so comments and formatting of untouched nodes are preserved.
+//
+// Arguments:
+// - root: The root mapping node of the configuration document.
+//
+// Returns:
+// - A warning for every deprecated construct that was upgraded.
+// - Whether the document was changed, i.e. a migration was applied or the version field was added or updated.
+// - An error if the version is invalid or newer than CurrentVersion.
+func Migrate(root *yaml.Node) ([]Problem, bool, error) {
+	version, err := documentVersion(root)
+	if err != nil {
+		return nil, false, err
+	}
+	_, versionNode := mappingValue(root, "version")
+	changed := versionNode == nil || version != CurrentVersion
+
+	var warnings []Problem
+	for _, m := range migrations {
+		if m.from >= version {
+			warnings = append(warnings, m.apply(root)...)
+			changed = true
+		}
+	}
+
+	if changed {
+		setVersion(root, CurrentVersion)
+	}
+	return warnings, changed, nil
+}
+
+// documentVersion returns the version declared in the configuration document.
+func documentVersion(root *yaml.Node) (int, error) {
+	_, versionNode := mappingValue(root, "version")
+	if versionNode == nil {
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(versionNode.Value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("line %d: invalid configuration version %q", versionNode.Line, versionNode.Value)
+	}
+	if version > CurrentVersion {
+		return 0, fmt.Errorf("line %d: configuration version %d is newer than the supported version %d, please update commity", versionNode.Line, version, CurrentVersion)
+	}
+	return version, nil
+}
+
+// setVersion sets the version field of the document, adding it as the first field if it is missing.
+func setVersion(root *yaml.Node, version int) {
+	if _, versionNode := mappingValue(root, "version"); versionNode != nil {
+		versionNode.Value = strconv.Itoa(version)
+		versionNode.Tag = "!!int"
+		return
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	if len(root.Content) > 0 {
+		// Keep a leading file comment at the top of the document
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
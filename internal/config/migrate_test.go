@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		changed bool
+		wantErr bool
+	}{
+		{
+			name:    "missing version is added",
+			input:   "template: x\n",
+			want:    "version: 1\ntemplate: x\n",
+			changed: true,
+		},
+		{
+			name:    "leading comment stays at the top",
+			input:   "# commity\ntemplate: x\n",
+			want:    "# commity\nversion: 1\ntemplate: x\n",
+			changed: true,
+		},
+		{
+			name:  "current version",
+			input: "version: 1\ntemplate: x # kept\n",
+			want:  "version: 1\ntemplate: x # kept\n",
+		},
+		{name: "newer version", input: "version: 2\n", wantErr: true},
+		{name: "invalid version", input: "version: latest\n", wantErr: true},
+		{name: "zero version", input: "version: 0\n", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var document yaml.Node
+			if err := yaml.Unmarshal([]byte(test.input), &document); err != nil {
+				t.Fatal(err)
+			}
+			warnings, changed, err := Migrate(document.Content[0])
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Migrate() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(warnings) > 0 {
+				t.Errorf("Migrate() warnings = %v, want none", warnings)
+			}
+			if changed != test.changed {
+				t.Errorf("Migrate() changed = %v, want %v", changed, test.changed)
+			}
+			output, err := yaml.Marshal(&document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != test.want {
+				t.Errorf("Migrate() document = %q, want %q", output, test.want)
+			}
+		})
+	}
+}
+
+// TestMigrateApply checks that registered migrations are applied and their warnings returned.
+// The format has no older versions yet, so the test registers a migration renaming a field.
+func TestMigrateApply(t *testing.T) {
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = []migration{{from: 1, apply: func(root *yaml.Node) []Problem {
+		key, _ := mappingValue(root, "format")
+		if key == nil {
+			return nil
+		}
+		key.Value = "template"
+		return []Problem{{Line: key.Line, Column: key.Column, Message: fmt.Sprintf("field %q is deprecated, use %q", "format", "template"), Warning: true}}
+	}}}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte("version: 1\nformat: x # kept\n"), &document); err != nil {
+		t.Fatal(err)
+	}
+	warnings, changed, err := Migrate(document.Content[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("Migrate() changed = false, want true")
+	}
+
+	var got []string
+	for _, warning := range warnings {
+		got = append(got, warning.String())
+	}
+	want := []string{`2:1: warning: field "format" is deprecated, use "template"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Migrate() warnings = %q, want %q", got, want)
+	}
+
+	output, err := yaml.Marshal(&document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "version: 1\ntemplate: x # kept\n" {
+		t.Errorf("Migrate() document = %q", output)
+	}
+}
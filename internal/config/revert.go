@@ -0,0 +1,53 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Revert configures the values the revert command prefills in the form.
+type Revert struct {
+	Values map[string]string `yaml:"values"` // Template strings by entry name, evaluated with the data of the reverted commit
+}
+
+// defaultRevertValues are used if the configuration does not define revert values.
+// Values for entries that do not exist are ignored.
+var defaultRevertValues = map[string]string{
+	"type":   "revert",
+	"header": "{{ .subject }}",
+	"body":   "This reverts commit {{ .hash }}.",
+}
+
+// RevertValues evaluates the revert values for the entries of the configuration.
+//
+// Arguments:
+// - data: The data of the reverted commit available to the templates (hash, shortHash, subject, body and author).
+//
+// Returns:
+// - The values by entry name, only for entries that exist.
+// - An error if a template cannot be evaluated.
+func (c *Configuration) RevertValues(data map[string]string) (map[string]string, error) {
+	templates := c.Revert.Values
+	if templates == nil {
+		templates = defaultRevertValues
+	}
+
+	values := make(map[string]string)
+	for _, entry := range c.Entries {
+		text, ok := templates[entry.GetName()]
+		if !ok {
+			continue
+		}
+		tmpl, err := template.New(entry.GetName()).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse revert value of %q: %w", entry.GetName(), err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to evaluate revert value of %q: %w", entry.GetName(), err)
+		}
+		values[entry.GetName()] = buf.String()
+	}
+	return values, nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Rule is a validation rule spanning several entries, checked once all values are known.
+//
+// The when and assert fields are template expressions without the surrounding braces,
+// e.g. `eq .type "revert"` or `match "[0-9a-f]{7,40}" .body`. They are evaluated like the condition of
+// an `if` action, so empty strings, zero numbers and false are false and all other values true.
+type Rule struct {
+	Field   string `yaml:"field"`   // The entry the rule violation is reported for (optional)
+	When    string `yaml:"when"`    // An expression restricting when the rule applies (optional, always applies if empty)
+	Assert  string `yaml:"assert"`  // An expression that must evaluate to true
+	Message string `yaml:"message"` // The message shown if the assertion fails
+}
+
+// expressionPrefix and expressionSuffix turn a rule expression into a template printing whether it is true.
+const (
+	expressionPrefix = "{{ if "
+	expressionSuffix = " }}true{{ else }}false{{ end }}"
+)
+
+// ruleFuncs are the functions available in rule expressions in addition to the template builtins.
+var ruleFuncs = template.FuncMap{
+	"match": func(pattern string, s string) (bool, error) {
+		return regexp.MatchString(pattern, s)
+	},
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"trim":      strings.TrimSpace,
+	"words": func(s string) int {
+		return len(strings.Fields(s))
+	},
+}
+
+// parseExpression parses a rule expression into a template printing "true" or "false".
+func parseExpression(name string, expression string) (*template.Template, error) {
+	return template.New(name).Funcs(ruleFuncs).Parse(expressionPrefix + expression + expressionSuffix)
+}
+
+// Check evaluates the rule against the given entry values.
+//
+// Arguments:
+// - values: The values of all entries by name, as passed to the commit message template.
+//
+// Returns:
+// - Whether the rule is satisfied. Rules whose when expression evaluates to false are always satisfied.
+// - An error if an expression cannot be evaluated.
+func (r *Rule) Check(values map[string]interface{}) (bool, error) {
+	if r.When != "" {
+		applies, err := evaluateExpression("when", r.When, values)
+		if err != nil || !applies {
+			return true, err
+		}
+	}
+	return evaluateExpression("assert", r.Assert, values)
+}
+
+// evaluateExpression evaluates a rule expression to its truth value.
+func evaluateExpression(name string, expression string, values map[string]interface{}) (bool, error) {
+	tmpl, err := parseExpression(name, expression)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse rule expression %q: %w", expression, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return false, fmt.Errorf("failed to evaluate rule expression %q: %w", expression, err)
+	}
+
+	return buf.String() == "true", nil
+}
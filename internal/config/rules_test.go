@@ -0,0 +1,86 @@
+package config
+
+import "testing"
+
+func TestRuleCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		values  map[string]interface{}
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "assert holds",
+			rule:   Rule{When: `eq .type "revert"`, Assert: `match "[0-9a-f]{7,40}" .body`},
+			values: map[string]interface{}{"type": "revert", "body": "This reverts commit 1a2b3c4d."},
+			want:   true,
+		},
+		{
+			name:   "assert fails",
+			rule:   Rule{When: `eq .type "revert"`, Assert: `match "[0-9a-f]{7,40}" .body`},
+			values: map[string]interface{}{"type": "revert", "body": "oops"},
+			want:   false,
+		},
+		{
+			name:   "when is false",
+			rule:   Rule{When: `eq .type "revert"`, Assert: `false`},
+			values: map[string]interface{}{"type": "feat"},
+			want:   true,
+		},
+		{
+			name:   "non-empty string operand applies",
+			rule:   Rule{When: `.scope`, Assert: `not (contains (lower .header) (lower .scope))`},
+			values: map[string]interface{}{"scope": "ui", "header": "Fix UI layout"},
+			want:   false,
+		},
+		{
+			name:   "empty string operand does not apply",
+			rule:   Rule{When: `.scope`, Assert: `false`},
+			values: map[string]interface{}{"scope": ""},
+			want:   true,
+		},
+		{
+			name:   "number operand",
+			rule:   Rule{When: `.count`, Assert: `false`},
+			values: map[string]interface{}{"count": int64(0)},
+			want:   true,
+		},
+		{
+			name:   "float operand with float literal",
+			rule:   Rule{Assert: `gt .hours 2.0`},
+			values: map[string]interface{}{"hours": float64(3)},
+			want:   true,
+		},
+		{
+			name:   "string assert",
+			rule:   Rule{Assert: `.ticket`},
+			values: map[string]interface{}{"ticket": "PROJ-1"},
+			want:   true,
+		},
+		{
+			name:   "missing entry is false",
+			rule:   Rule{Assert: `.ticket`},
+			values: map[string]interface{}{},
+			want:   false,
+		},
+		{
+			name:    "evaluation error",
+			rule:    Rule{Assert: `match "[" .body`},
+			values:  map[string]interface{}{"body": "x"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.rule.Check(test.values)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err == nil && got != test.want {
+				t.Errorf("Check() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
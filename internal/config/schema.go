@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// entryInterface is the reflected type of the Entry interface, which is rendered as a discriminated union.
+var entryInterface = reflect.TypeOf((*Entry)(nil)).Elem()
+
+// Schema generates a JSON Schema describing the configuration file.
+// The schema is derived from the yaml tags of the configuration structs, so new fields are picked up automatically.
+// Entries are described as a union discriminated by their type field, matching the decoding in UnmarshalYAML.
+//
+// Returns:
+// - The JSON Schema as indented JSON, or an error if it cannot be encoded.
+func Schema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(Configuration{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Commity configuration"
+
+	defs := make(map[string]interface{})
+	for _, name := range entryTypeNames() {
+		def := typeSchema(entryTypes[name])
+		def["properties"].(map[string]interface{})["type"] = map[string]interface{}{"const": name}
+		def["required"] = []string{"type", "name"}
+		defs[name+"Entry"] = def
+	}
+	schema["$defs"] = defs
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaProvider is implemented by types with a custom YAML representation that describe their own schema.
+type schemaProvider interface {
+	jsonSchema() map[string]interface{}
+}
+
+// typeSchema returns the JSON Schema for a Go type.
+func typeSchema(typ reflect.Type) map[string]interface{} {
+	if typ == entryInterface {
+		return entrySchema()
+	}
+	if provider, ok := reflect.Zero(typ).Interface().(schemaProvider); ok {
+		return provider.jsonSchema()
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return typeSchema(typ.Elem())
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for name, field := range yamlFields(typ) {
+			properties[name] = typeSchema(field.Type)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(typ.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(typ.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// entrySchema returns the schema of a single entry.
+// The type field selects which entry definition the rest of the object is validated against.
+func entrySchema() map[string]interface{} {
+	names := entryTypeNames()
+	var variants []interface{}
+	for _, name := range names {
+		variants = append(variants, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": name}},
+			},
+			"then": map[string]interface{}{"$ref": "#/$defs/" + name + "Entry"},
+		})
+	}
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"type"},
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{"enum": names},
+		},
+		"allOf": variants,
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// samplePatternValues are tried in order for string properties restricted by a pattern.
+var samplePatternValues = []string{"7d", "12h", "1MB"}
+
+// TestSchemaRoundTrip builds a configuration setting every property of the schema, parses it and checks
+// that every property arrives in the parsed configuration. A field missing in the schema, or a property
+// of the schema dropped while decoding, e.g. because a custom UnmarshalYAML does not copy it, fails the test.
+func TestSchemaRoundTrip(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	defs := schema["$defs"].(map[string]interface{})
+
+	sample := sampleValue(t, "configuration", schema, defs)
+	document, err := yaml.Marshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), ".commity.yaml")
+	if err := os.WriteFile(path, document, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ParseConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseConfigFile() rejected the sample generated from the schema: %v\n%s", err, document)
+	}
+	checkDecoded(t, "configuration", reflect.ValueOf(cfg), sample)
+
+	// Every entry type is covered by the sample
+	var types []string
+	for _, entry := range cfg.Entries {
+		types = append(types, strings.TrimSuffix(strings.TrimPrefix(reflect.TypeOf(entry).String(), "*config."), "Entry"))
+	}
+	if !reflect.DeepEqual(types, entryTypeNames()) {
+		t.Errorf("sample entries have types %v, want %v", types, entryTypeNames())
+	}
+}
+
+// TestSchemaRejectsUnknownFields checks that objects of the schema are closed like the strict mode of the parser.
+func TestSchemaRejectsUnknownFields(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["additionalProperties"] != false {
+		t.Errorf("configuration allows additional properties")
+	}
+	for name, def := range schema["$defs"].(map[string]interface{}) {
+		if def.(map[string]interface{})["additionalProperties"] != false {
+			t.Errorf("%s allows additional properties", name)
+		}
+	}
+}
+
+// sampleValue returns a value for the schema that sets every property it describes.
+func sampleValue(t *testing.T, path string, schema map[string]interface{}, defs map[string]interface{}) interface{} {
+	t.Helper()
+	if ref, ok := schema["$ref"].(string); ok {
+		return sampleValue(t, path, defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{}), defs)
+	}
+	if value, ok := schema["const"]; ok {
+		return value
+	}
+	if values, ok := schema["enum"].([]interface{}); ok {
+		return values[0]
+	}
+	if variants, ok := schema["oneOf"].([]interface{}); ok {
+		// Objects describe the most fields, so they are preferred over the scalar shorthands
+		chosen := variants[0].(map[string]interface{})
+		for _, variant := range variants {
+			if variant.(map[string]interface{})["type"] == "object" {
+				chosen = variant.(map[string]interface{})
+			}
+		}
+		return sampleValue(t, path, chosen, defs)
+	}
+
+	switch schema["type"] {
+	case "object":
+		properties, _ := schema["properties"].(map[string]interface{})
+		if len(properties) == 0 {
+			if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				return map[string]interface{}{"key": sampleValue(t, path+".key", values, defs)}
+			}
+		}
+		object := make(map[string]interface{})
+		for name, property := range properties {
+			object[name] = sampleValue(t, path+"."+name, property.(map[string]interface{}), defs)
+		}
+		return object
+	case "array":
+		items := schema["items"].(map[string]interface{})
+		if variants, ok := items["allOf"].([]interface{}); ok {
+			// One item for every variant of a discriminated union
+			var list []interface{}
+			for _, variant := range variants {
+				then := variant.(map[string]interface{})["then"].(map[string]interface{})
+				list = append(list, sampleValue(t, path+"[]", then, defs))
+			}
+			return list
+		}
+		return []interface{}{sampleValue(t, path+"[]", items, defs)}
+	case "string":
+		pattern, ok := schema["pattern"].(string)
+		if !ok {
+			// Accepted by every string field, including templates, expressions, regular expressions and globs
+			return "true"
+		}
+		for _, value := range samplePatternValues {
+			if regexp.MustCompile(pattern).MatchString(value) {
+				return value
+			}
+		}
+		t.Fatalf("%s: no sample value matches the pattern %s", path, pattern)
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	}
+	t.Fatalf("%s: cannot build a sample for the schema %v", path, schema)
+	return nil
+}
+
+// checkDecoded checks that every value of the sample is set in the decoded value.
+func checkDecoded(t *testing.T, path string, value reflect.Value, sample interface{}) {
+	t.Helper()
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			t.Errorf("%s is not decoded", path)
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch sample := sample.(type) {
+	case map[string]interface{}:
+		if value.Kind() == reflect.Map {
+			for key, item := range sample {
+				checkDecoded(t, path+"."+key, value.MapIndex(reflect.ValueOf(key)), item)
+			}
+			return
+		}
+		fields := yamlFields(value.Type())
+		for key, item := range sample {
+			if key == "type" && strings.HasSuffix(value.Type().Name(), "Entry") {
+				// The discriminator selects the struct and has no field
+				continue
+			}
+			field, ok := fields[key]
+			if !ok {
+				t.Errorf("%s.%s is in the schema but not a field of %s", path, key, value.Type())
+				continue
+			}
+			checkDecoded(t, path+"."+key, value.FieldByIndex(field.Index), item)
+		}
+	case []interface{}:
+		if value.Len() != len(sample) {
+			t.Errorf("%s has %d items, want %d", path, value.Len(), len(sample))
+			return
+		}
+		for i, item := range sample {
+			checkDecoded(t, path+"[]", value.Index(i), item)
+		}
+	default:
+		if !value.IsValid() || value.IsZero() {
+			t.Errorf("%s is not decoded", path)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SecretScan configures the scan of the staged changes for secrets and credentials.
+type SecretScan struct {
+	Patterns []SecretPattern `yaml:"patterns"` // Additional patterns besides the built-in rules
+	Entropy  *float64        `yaml:"entropy"`  // Minimum Shannon entropy of random-looking tokens reported as secret (default 4.5, 0 = disabled)
+	Ignore   []string        `yaml:"ignore"`   // Globs of paths that are not scanned (e.g. "testdata/**")
+}
+
+// SecretPattern is a named regular expression matching a secret.
+type SecretPattern struct {
+	Name    string `yaml:"name"`    // The name shown for matches (e.g. "Internal API token")
+	Pattern string `yaml:"pattern"` // The regular expression matching the secret
+}
+
+// defaultEntropy is the default minimum entropy of tokens reported as secret.
+// Random hex strings like commit hashes stay below it, base64 encoded keys usually exceed it.
+const defaultEntropy = 4.5
+
+// builtinSecretPatterns lists the rules every scan checks.
+var builtinSecretPatterns = []SecretPattern{
+	{Name: "AWS access key", Pattern: `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "GitHub token", Pattern: `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`},
+	{Name: "GitLab token", Pattern: `\bglpat-[A-Za-z0-9_-]{20,}`},
+	{Name: "Slack token", Pattern: `\bxox[abposr]-[A-Za-z0-9-]{10,}`},
+	{Name: "Stripe key", Pattern: `\b[rs]k_live_[A-Za-z0-9]{20,}`},
+	{Name: "Google API key", Pattern: `\bAIza[0-9A-Za-z_-]{35}`},
+	{Name: "API secret key", Pattern: `\bsk-[A-Za-z0-9_-]{32,}`},
+	{Name: "Private key", Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`},
+	{Name: "JSON web token", Pattern: `\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`},
+	{Name: "Password assignment", Pattern: `(?i)(password|passwd|secret|api[_-]?key|access[_-]?token)["']?\s*[:=]\s*["'][^"'\s]{8,}["']`},
+}
+
+// tokenPattern matches candidate tokens for the entropy detection.
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// hashTokenPattern matches tokens starting with the name of a hash algorithm, like the integrity hashes of npm.
+var hashTokenPattern = regexp.MustCompile(`(?i)^sha(1|224|256|384|512)-`)
+
+// hashPrefixPattern matches the text before a token that marks it as hash, e.g. "h1:" in go.sum.
+var hashPrefixPattern = regexp.MustCompile(`(?i)\b(h1|sha(1|224|256|384|512)):$`)
+
+// lockfiles lists files of package managers consisting of checksums, which the entropy detection skips.
+var lockfiles = []string{
+	"go.sum", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock",
+	"Cargo.lock", "poetry.lock", "Pipfile.lock", "uv.lock", "Gemfile.lock", "composer.lock", "flake.lock",
+}
+
+// SecretScanner checks lines for secrets. It is created from a SecretScan with NewSecretScanner.
+type SecretScanner struct {
+	patterns []*regexp.Regexp // The compiled built-in and configured patterns
+	names    []string         // The names of the patterns
+	entropy  float64          // The minimum entropy of reported tokens, 0 if disabled
+	ignore   []string         // Globs of paths that are not scanned
+}
+
+// NewSecretScanner compiles the built-in rules and the patterns of the scan configuration.
+//
+// Returns:
+// - The scanner, or an error if a configured pattern is invalid.
+func NewSecretScanner(scan *SecretScan) (*SecretScanner, error) {
+	s := &SecretScanner{entropy: defaultEntropy, ignore: scan.Ignore}
+	if scan.Entropy != nil {
+		s.entropy = *scan.Entropy
+	}
+	for _, p := range append(append([]SecretPattern{}, builtinSecretPatterns...), scan.Patterns...) {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		s.patterns = append(s.patterns, re)
+		s.names = append(s.names, p.Name)
+	}
+	return s, nil
+}
+
+// Ignored reports whether the file at the given slash-separated path is excluded from the scan.
+func (s *SecretScanner) Ignored(file string) bool {
+	for _, glob := range s.ignore {
+		if MatchGlob(glob, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanLine returns the names of all rules matching a line of the file at the given slash-separated path.
+// The entropy detection skips lockfiles and tokens marked as hash, as they consist of checksums.
+func (s *SecretScanner) ScanLine(file string, line string) []string {
+	var matches []string
+	for i, re := range s.patterns {
+		if re.MatchString(line) {
+			matches = append(matches, s.names[i])
+		}
+	}
+	if len(matches) == 0 && s.entropy > 0 && !isLockfile(file) {
+		for _, loc := range tokenPattern.FindAllStringIndex(line, -1) {
+			token := line[loc[0]:loc[1]]
+			if hashTokenPattern.MatchString(token) || hashPrefixPattern.MatchString(line[:loc[0]]) {
+				continue
+			}
+			if strings.ContainsAny(token, "0123456789") && strings.IndexFunc(token, isLetter) >= 0 && shannonEntropy(token) >= s.entropy {
+				matches = append(matches, "High-entropy string")
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// isLockfile reports whether the file at the given slash-separated path is a lockfile of a package manager.
+func isLockfile(file string) bool {
+	name := path.Base(file)
+	for _, lockfile := range lockfiles {
+		if name == lockfile {
+			return true
+		}
+	}
+	return false
+}
+
+// isLetter reports whether the rune is an ASCII letter.
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// shannonEntropy computes the Shannon entropy of the string in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
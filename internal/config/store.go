@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StoreScope is the scope in which the value of an entry with `store: true` is kept between runs.
+type StoreScope string
+
+const (
+	ScopeRepository StoreScope = "repository" // Shared by all branches and worktrees of the repository (default)
+	ScopeBranch     StoreScope = "branch"     // Kept per branch
+	ScopeWorktree   StoreScope = "worktree"   // Kept per worktree of the repository
+	ScopeGlobal     StoreScope = "global"     // Shared by all repositories
+)
+
+// storeScopes lists the valid store scopes.
+var storeScopes = []string{string(ScopeRepository), string(ScopeBranch), string(ScopeWorktree), string(ScopeGlobal)}
+
+// OrDefault returns the scope, or the repository scope if none is set.
+func (s StoreScope) OrDefault() StoreScope {
+	if s == "" {
+		return ScopeRepository
+	}
+	return s
+}
+
+// UnmarshalYAML handles the deserialization of a StoreScope, rejecting unknown scopes.
+func (s *StoreScope) UnmarshalYAML(value *yaml.Node) error {
+	if !contains(storeScopes, value.Value) {
+		return nodeErrorf(value, "invalid store scope %q, expected one of: %s", value.Value, strings.Join(storeScopes, ", "))
+	}
+	*s = StoreScope(value.Value)
+	return nil
+}
+
+// jsonSchema describes the scope as one of the valid scope names.
+func (StoreScope) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{"enum": storeScopes}
+}
+
+// Duration is a period of time. It is written like a Go duration (e.g. "12h" or "90m"),
+// or as a number of days or weeks, e.g. "7d" or "2w".
+type Duration time.Duration
+
+// durationUnits lists the units supported in addition to those of Go durations.
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// UnmarshalYAML handles the deserialization of a Duration.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	duration, err := ParseDuration(value.Value)
+	if err != nil {
+		return nodeErrorf(value, "%v", err)
+	}
+	*d = duration
+	return nil
+}
+
+// jsonSchema describes a duration as string with units.
+func (Duration) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "string",
+		"pattern": `^\s*([0-9]+[dw]|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)\s*$`,
+	}
+}
+
+// ParseDuration parses a duration like "7d", "2w" or "36h". Negative durations are rejected.
+func ParseDuration(input string) (Duration, error) {
+	text := strings.TrimSpace(input)
+	for suffix, unit := range durationUnits {
+		if number, ok := strings.CutSuffix(text, suffix); ok {
+			count, err := strconv.Atoi(number)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid duration %q, expected e.g. \"12h\", \"7d\" or \"2w\"", input)
+			}
+			return Duration(time.Duration(count) * unit), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(text)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected e.g. \"12h\", \"7d\" or \"2w\"", input)
+	}
+	return Duration(duration), nil
+}
+
+// Cache configures how the values of entries with `store: true` are kept.
+type Cache struct {
+	Identity CacheIdentity `yaml:"identity"` // How the repository is identified: path (default), remote or root
+}
+
+// CacheIdentity is the strategy identifying a repository in the cache.
+type CacheIdentity string
+
+const (
+	IdentityPath   CacheIdentity = "path"   // The path of the main worktree (default)
+	IdentityRemote CacheIdentity = "remote" // The URL of the origin remote, shared by all clones
+	IdentityRoot   CacheIdentity = "root"   // The hash of the root commit, shared by all clones and forks
+)
+
+// CacheIdentities lists the valid identity strategies.
+var CacheIdentities = []CacheIdentity{IdentityPath, IdentityRemote, IdentityRoot}
+
+// OrDefault returns the identity strategy, or the path strategy if none is set.
+func (i CacheIdentity) OrDefault() CacheIdentity {
+	if i == "" {
+		return IdentityPath
+	}
+	return i
+}
+
+// UnmarshalYAML handles the deserialization of a CacheIdentity, rejecting unknown strategies.
+func (i *CacheIdentity) UnmarshalYAML(value *yaml.Node) error {
+	if !contains(cacheIdentityNames(), value.Value) {
+		return nodeErrorf(value, "invalid cache identity %q, expected one of: %s", value.Value, strings.Join(cacheIdentityNames(), ", "))
+	}
+	*i = CacheIdentity(value.Value)
+	return nil
+}
+
+// jsonSchema describes the identity as one of the valid strategy names.
+func (CacheIdentity) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{"enum": cacheIdentityNames()}
+}
+
+// cacheIdentityNames returns the names of the valid identity strategies.
+func cacheIdentityNames() []string {
+	names := make([]string, len(CacheIdentities))
+	for i, identity := range CacheIdentities {
+		names[i] = string(identity)
+	}
+	return names
+}
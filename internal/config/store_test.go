@@ -0,0 +1,83 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{" 36h ", 36 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"0d", 0, false},
+		{"-1d", 0, true},
+		{"-2h", 0, true},
+		{"1.5d", 0, true},
+		{"d", 0, true},
+		{"soon", 0, true},
+		{"", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDuration(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+			continue
+		}
+		if time.Duration(got) != test.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", test.input, time.Duration(got), test.want)
+		}
+	}
+}
+
+func TestUnmarshalDuration(t *testing.T) {
+	var entry TextEntry
+	if err := yaml.Unmarshal([]byte("storeTTL: 2w"), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if want := 14 * 24 * time.Hour; time.Duration(entry.StoreTTL) != want {
+		t.Errorf("StoreTTL = %v, want %v", time.Duration(entry.StoreTTL), want)
+	}
+
+	if err := yaml.Unmarshal([]byte("storeTTL: forever"), &entry); err == nil {
+		t.Error("Unmarshal() of an invalid storeTTL succeeded, want an error")
+	}
+}
+
+func TestUnmarshalStoreOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid scope", "scope: branch", false},
+		{"capitalized scope", "scope: Branch", true},
+		{"unknown scope", "scope: project", true},
+		{"valid identity", "identity: remote", false},
+		{"unknown identity", "identity: url", true},
+		{"valid ttl", "ttl: 2w", false},
+		{"invalid ttl", "ttl: forever", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var target struct {
+				Scope    StoreScope    `yaml:"scope"`
+				Identity CacheIdentity `yaml:"identity"`
+				TTL      Duration      `yaml:"ttl"`
+			}
+			err := yaml.Unmarshal([]byte(test.input), &target)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Unmarshal(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+			}
+		})
+	}
+}
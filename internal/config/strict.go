@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unknownFields walks the node and reports every mapping key that does not correspond to a field of the given type.
+// Entries are checked against the struct selected by their type field.
+//
+// Arguments:
+// - node: The YAML node to check.
+// - typ: The Go type the node is decoded into.
+// - context: A short description of the node used in the messages (e.g. "configuration").
+//
+// Returns:
+// - A problem for every unknown key, including a suggestion for the closest valid key if there is one.
+func unknownFields(node *yaml.Node, typ reflect.Type, context string) []Problem {
+	if node == nil {
+		return nil
+	}
+
+	if typ == entryInterface {
+		_, typeNode := mappingValue(node, "type")
+		if typeNode == nil {
+			return nil
+		}
+		entryType, ok := entryTypes[typeNode.Value]
+		if !ok {
+			return nil // reported when decoding the entry
+		}
+		context = typeNode.Value + " entry"
+		if _, nameNode := mappingValue(node, "name"); nameNode != nil && nameNode.Value != "" {
+			context = fmt.Sprintf("%s entry %q", typeNode.Value, nameNode.Value)
+		}
+		return structUnknownFields(node, entryType, context, "type")
+	}
+
+	var problems []Problem
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return unknownFields(node, typ.Elem(), context)
+	case reflect.Struct:
+		return structUnknownFields(node, typ, context)
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range node.Content {
+			problems = append(problems, unknownFields(item, typ.Elem(), context)...)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, unknownFields(node.Content[i+1], typ.Elem(), context)...)
+		}
+	}
+	return problems
+}
+
+// structUnknownFields checks the keys of a mapping node against the yaml fields of a struct type.
+// Keys listed in extra are accepted in addition to the struct fields.
+func structUnknownFields(node *yaml.Node, typ reflect.Type, context string, extra ...string) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	fields := yamlFields(typ)
+	valid := append([]string{}, extra...)
+	for name := range fields {
+		valid = append(valid, name)
+	}
+	sort.Strings(valid)
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field, known := fields[key.Value]
+		if !known {
+			if contains(extra, key.Value) {
+				continue
+			}
+			message := fmt.Sprintf("unknown field %q in %s", key.Value, context)
+			if suggestion := closestMatch(key.Value, valid); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			problems = append(problems, Problem{Line: key.Line, Column: key.Column, Message: message})
+			continue
+		}
+		problems = append(problems, unknownFields(value, field.Type, fmt.Sprintf("%s of %s", key.Value, context))...)
+	}
+	return problems
+}
+
+// closestMatch returns the candidate closest to the given value, or an empty string if none is reasonably close.
+func closestMatch(value string, candidates []string) string {
+	best := ""
+	bestDistance := len(value)/3 + 2
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(value), strings.ToLower(candidate))
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// levenshtein computes the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// nodeError is an error tied to the position of a YAML node.
+type nodeError struct {
+	line    int    // The line of the node
+	column  int    // The column of the node
+	message string // The description of the error
+}
+
+// Error formats the error like the errors of the yaml package.
+// Nodes without position, e.g. converted from TOML, are reported without a line.
+func (e *nodeError) Error() string {
+	if e.line == 0 {
+		return e.message
+	}
+	return fmt.Sprintf("line %d: %s", e.line, e.message)
+}
+
+// nodeErrorf creates an error at the position of the given node.
+func nodeErrorf(node *yaml.Node, format string, args ...interface{}) error {
+	return &nodeError{line: node.Line, column: node.Column, message: fmt.Sprintf(format, args...)}
+}
+
+// problemList is an error listing several problems of a configuration file.
+// The problems are prefixed with the path of the file once it is known (see withPath).
+type problemList struct {
+	summary  string    // The description of all problems
+	problems []Problem // The individual problems
+	path     string    // The path of the configuration file, empty if unknown
+}
+
+// Error formats the summary followed by one problem per line.
+func (e *problemList) Error() string {
+	lines := make([]string, 0, len(e.problems)+1)
+	lines = append(lines, e.summary)
+	for _, problem := range e.problems {
+		if e.path != "" {
+			lines = append(lines, "  "+problem.Format(e.path))
+		} else {
+			lines = append(lines, "  "+problem.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// problemsError combines a list of problems into a single error.
+func problemsError(summary string, problems []Problem) error {
+	return &problemList{summary: summary, problems: problems}
+}
+
+// withPath adds the path of the configuration file to the problems listed by the error, if it is a problem list.
+func withPath(err error, path string) error {
+	var list *problemList
+	if errors.As(err, &list) {
+		list.path = path
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// spacesPattern matches runs of horizontal whitespace collapsed by the collapse-spaces transform.
+var spacesPattern = regexp.MustCompile(`[ \t]+`)
+
+// builtinTransforms maps the names of the built-in transforms to their implementation.
+var builtinTransforms = map[string]func(string) string{
+	"trim": strings.TrimSpace,
+	"lowercase-first": func(value string) string {
+		return mapFirstRune(value, unicode.ToLower)
+	},
+	"sentence-case": func(value string) string {
+		return mapFirstRune(value, unicode.ToUpper)
+	},
+	"strip-trailing-period": func(value string) string {
+		return strings.TrimRight(value, ".")
+	},
+	"collapse-spaces": func(value string) string {
+		return spacesPattern.ReplaceAllString(value, " ")
+	},
+}
+
+// Transform is a single normalization step applied to the value of a text entry.
+// It is either the name of a built-in transform (e.g. "trim") or a regular expression replacement.
+type Transform struct {
+	Name    string         `yaml:"-"`       // The name of the built-in transform, empty for replacements
+	Replace string         `yaml:"replace"` // Regular expression to replace
+	With    string         `yaml:"with"`    // Replacement text, may reference capture groups like ${1}
+	pattern *regexp.Regexp // The compiled Replace expression
+}
+
+// UnmarshalYAML handles the deserialization of a Transform, which is either a scalar naming a built-in transform
+// or a mapping describing a replacement.
+func (t *Transform) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if _, ok := builtinTransforms[value.Value]; !ok {
+			return nodeErrorf(value, "unknown transform %q (expected one of %s or a replace mapping)", value.Value, strings.Join(transformNames(), ", "))
+		}
+		t.Name = value.Value
+		return nil
+	}
+
+	var raw struct {
+		Replace string `yaml:"replace"`
+		With    string `yaml:"with"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	if raw.Replace == "" {
+		return nodeErrorf(value, "replace transform is missing the replace pattern")
+	}
+	pattern, err := regexp.Compile(raw.Replace)
+	if err != nil {
+		return nodeErrorf(value, "invalid replace pattern: %v", err)
+	}
+
+	t.Replace = raw.Replace
+	t.With = raw.With
+	t.pattern = pattern
+	return nil
+}
+
+// Apply applies the transform to the value.
+func (t *Transform) Apply(value string) string {
+	if t.pattern != nil {
+		return t.pattern.ReplaceAllString(value, t.With)
+	}
+	if transform, ok := builtinTransforms[t.Name]; ok {
+		return transform(value)
+	}
+	return value
+}
+
+// jsonSchema describes a transform as either a built-in name or a replacement mapping.
+func (Transform) jsonSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"enum": transformNames()},
+			map[string]interface{}{
+				"type":                 "object",
+				"required":             []string{"replace"},
+				"additionalProperties": false,
+				"properties": map[string]interface{}{
+					"replace": map[string]interface{}{"type": "string"},
+					"with":    map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+}
+
+// ApplyTransforms returns the value after applying all transforms of the entry in order.
+func (e *TextEntry) ApplyTransforms(value string) string {
+	for i := range e.Transform {
+		value = e.Transform[i].Apply(value)
+	}
+	return value
+}
+
+// transformNames returns the sorted names of the built-in transforms.
+func transformNames() []string {
+	names := make([]string, 0, len(builtinTransforms))
+	for name := range builtinTransforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mapFirstRune applies the mapping to the first rune of the value.
+func mapFirstRune(value string, mapping func(rune) rune) string {
+	r, size := utf8.DecodeRuneInString(value)
+	if r == utf8.RuneError {
+		return value
+	}
+	return string(mapping(r)) + value[size:]
+}
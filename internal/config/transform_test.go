@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyTransforms(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+		input     string
+		want      string
+	}{
+		{"trim", "[trim]", "  add cache \n", "add cache"},
+		{"collapse spaces", "[collapse-spaces]", "add \t the  cache", "add the cache"},
+		{"lowercase first", "[lowercase-first]", "Add cache", "add cache"},
+		{"lowercase first of empty value", "[lowercase-first]", "", ""},
+		{"sentence case", "[sentence-case]", "über cache", "Über cache"},
+		{"strip trailing period", "[strip-trailing-period]", "Add cache...", "Add cache"},
+		{"replace with capture group", `[{replace: '^#(\d+)$', with: 'GH-${1}'}]`, "#42", "GH-42"},
+		{"replace without with", `[{replace: '(?i)^wip:?\s*'}]`, "WIP: add cache", "add cache"},
+		{"applied in order", `[trim, {replace: '^wip\s*'}, sentence-case, strip-trailing-period]`, " wip add cache. ", "Add cache"},
+		{"order matters", `[strip-trailing-period, trim]`, "add cache. ", "add cache."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entry TextEntry
+			if err := yaml.Unmarshal([]byte("transform: "+test.transform), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if got := entry.ApplyTransforms(test.input); got != test.want {
+				t.Errorf("ApplyTransforms(%q) = %q, want %q", test.input, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+	}{
+		{"unknown transform", "[uppercase]"},
+		{"missing replace", "[{with: x}]"},
+		{"invalid expression", "[{replace: '('}]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entry TextEntry
+			if err := yaml.Unmarshal([]byte("transform: "+test.transform), &entry); err == nil {
+				t.Errorf("Unmarshal(%q) succeeded, want an error", test.transform)
+			}
+		})
+	}
+}
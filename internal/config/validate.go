@@ -0,0 +1,830 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem describes a single issue found while validating a configuration file.
+// Line and Column are 1-based positions within the configuration file, or 0 if the position is unknown
+// (e.g. for formats which do not provide positions like TOML).
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+	Warning bool // Whether the problem is only a warning (e.g. a deprecation) that does not invalidate the file
+}
+
+// String formats the problem as "line:column: message".
+func (p Problem) String() string {
+	message := p.Message
+	if p.Warning {
+		message = "warning: " + message
+	}
+	if p.Line == 0 {
+		return message
+	}
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, message)
+}
+
+// Format formats the problem as "path:line:column: message".
+func (p Problem) Format(path string) string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", path, p)
+	}
+	return fmt.Sprintf("%s:%s", path, p)
+}
+
+// entryTypes maps the type discriminator used in the configuration file to the struct the entry decodes into.
+var entryTypes = map[string]reflect.Type{
+	"Text":     reflect.TypeOf(TextEntry{}),
+	"Choice":   reflect.TypeOf(ChoiceEntry{}),
+	"Boolean":  reflect.TypeOf(BooleanEntry{}),
+	"Number":   reflect.TypeOf(NumberEntry{}),
+	"Computed": reflect.TypeOf(ComputedEntry{}),
+}
+
+// entryTypeNames returns the sorted list of known entry type discriminators.
+func entryTypeNames() []string {
+	names := make([]string, 0, len(entryTypes))
+	for name := range entryTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// yamlLinePattern extracts the line number from the messages produced by the yaml and toml packages.
+var yamlLinePattern = regexp.MustCompile(`line (\d+)(?: \([^)]*\))?: (.*)`)
+
+// templateLinePattern extracts the line number from the errors produced by text/template.
+var templateLinePattern = regexp.MustCompile(`^template: [^:]*:(\d+): (.*)$`)
+
+// Validate reads the configuration file at the given path and checks it for problems.
+// Unlike ParseConfigFile it does not stop at the first error, but reports every problem it finds
+// together with its position in the file.
+//
+// Arguments:
+// - path: The file path to the configuration file.
+//
+// Returns:
+// - The list of problems found, sorted by their position. The configuration is valid if all of them are warnings.
+// - An error if the file cannot be read.
+func Validate(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{source: strings.Split(string(data), "\n"), dir: filepath.Dir(path)}
+	v.validate(path)
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		if v.problems[i].Line != v.problems[j].Line {
+			return v.problems[i].Line < v.problems[j].Line
+		}
+		return v.problems[i].Column < v.problems[j].Column
+	})
+	return v.problems, nil
+}
+
+// validator collects the problems found in a single configuration file.
+type validator struct {
+	source   []string  // The lines of the configuration file
+	dir      string    // The directory of the configuration file
+	problems []Problem // The problems found so far
+}
+
+// report records a problem at the position of the given node.
+func (v *validator) report(node *yaml.Node, format string, args ...interface{}) {
+	v.reportAt(node.Line, node.Column, format, args...)
+}
+
+// reportAt records a problem at the given position. A line of 0 means the position is unknown.
+func (v *validator) reportAt(line, column int, format string, args ...interface{}) {
+	if line < 1 {
+		line, column = 0, 0
+	} else if column < 1 {
+		column = 1
+	}
+	v.problems = append(v.problems, Problem{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate runs all checks on the configuration file.
+func (v *validator) validate(path string) {
+	root, err := readConfigNode(path)
+	if err != nil {
+		v.reportYAMLError(err, 0)
+		return
+	}
+	if root.Kind != yaml.MappingNode {
+		v.report(root, "configuration must be a mapping")
+		return
+	}
+
+	// Upgrade older versions first, the remaining checks apply to the current format
+	warnings, _, err := Migrate(root)
+	if err != nil {
+		v.reportYAMLError(err, root.Line)
+		return
+	}
+	v.problems = append(v.problems, warnings...)
+	v.problems = append(v.problems, interpolateNode(root, v.dir)...)
+
+	// Check the plain top-level fields, entries and branches are handled separately
+	v.decodeFields(root, reflect.TypeOf(Configuration{}), "entries", "branches")
+
+	entries := v.validateEntries(root)
+	v.validateComputed(entries)
+	if _, templateNode := mappingValue(root, "template"); templateNode == nil || templateNode.Value == "" {
+		v.report(root, "no template defined")
+	} else {
+		v.validateTemplate(templateNode, entries)
+	}
+	v.validateBranches(root, entries)
+	v.validateRules(root, entries)
+	v.validateSecrets(root)
+	v.validateGuards(root)
+	v.validateRevert(root, entries)
+
+	// Unknown fields are only an error in strict mode
+	if _, strictNode := mappingValue(root, "strict"); strictNode == nil || strictNode.Value != "false" {
+		v.problems = append(v.problems, unknownFields(root, reflect.TypeOf(Configuration{}), "configuration")...)
+	}
+}
+
+// entryInfo holds what is known about a valid entry for checks referring to it.
+type entryInfo struct {
+	typ      string          // The type of the entry
+	choices  map[string]bool // The values of the choices if the entry is a choice entry
+	template *yaml.Node      // The template node if the entry is a computed entry
+}
+
+// validateEntries checks the entries section and returns all valid entries by name.
+func (v *validator) validateEntries(root *yaml.Node) map[string]*entryInfo {
+	_, entries := mappingValue(root, "entries")
+	if entries == nil {
+		v.report(root, "no entries defined")
+		return map[string]*entryInfo{}
+	}
+	if entries.Kind == yaml.SequenceNode && len(entries.Content) == 0 {
+		v.report(entries, "no entries defined")
+	}
+	return v.validateEntryList(entries)
+}
+
+// validateEntryList checks a list of entries and returns all valid entries by name.
+func (v *validator) validateEntryList(entries *yaml.Node) map[string]*entryInfo {
+	infos := make(map[string]*entryInfo)
+	if entries.Kind != yaml.SequenceNode {
+		v.report(entries, "entries must be a list")
+		return infos
+	}
+
+	definedAt := make(map[string]int)
+	for _, node := range entries.Content {
+		if node.Kind != yaml.MappingNode {
+			v.report(node, "entry must be a mapping")
+			continue
+		}
+
+		name := ""
+		_, nameNode := mappingValue(node, "name")
+		if nameNode == nil || nameNode.Value == "" {
+			v.report(node, "entry is missing a name")
+		} else if line, exists := definedAt[nameNode.Value]; exists {
+			v.report(nameNode, "duplicate entry name %q (first defined at line %d)", nameNode.Value, line)
+		} else {
+			definedAt[nameNode.Value] = nameNode.Line
+			name = nameNode.Value
+		}
+
+		_, typeNode := mappingValue(node, "type")
+		if typeNode == nil {
+			v.report(node, "entry is missing the type field")
+			continue
+		}
+		entryType, ok := entryTypes[typeNode.Value]
+		if !ok {
+			v.report(typeNode, "unknown entry type %q (expected one of %s)", typeNode.Value, strings.Join(entryTypeNames(), ", "))
+			continue
+		}
+
+		info := &entryInfo{typ: typeNode.Value}
+		if name != "" {
+			infos[name] = info
+		}
+
+		// Only run the semantic checks if all fields could be decoded
+		if !v.decodeFields(node, entryType, "type") {
+			continue
+		}
+		switch typeNode.Value {
+		case "Text":
+			v.validateTextEntry(node)
+		case "Choice":
+			info.choices = v.validateChoiceEntry(node)
+		case "Number":
+			v.validateNumberEntry(node)
+		case "Computed":
+			if _, templateNode := mappingValue(node, "template"); templateNode == nil || templateNode.Value == "" {
+				v.report(node, "computed entry %q has no template", name)
+			} else {
+				info.template = templateNode
+			}
+		}
+	}
+
+	return infos
+}
+
+// validateTextEntry checks the constraints of a text entry.
+func (v *validator) validateTextEntry(node *yaml.Node) {
+	var entry TextEntry
+	if err := node.Decode(&entry); err != nil {
+		v.reportYAMLError(err, node.Line)
+		return
+	}
+
+	if entry.MinLength < 0 {
+		_, minNode := mappingValue(node, "minLength")
+		v.report(minNode, "minLength must not be negative")
+	}
+	if entry.MaxLength < 0 {
+		_, maxNode := mappingValue(node, "maxLength")
+		v.report(maxNode, "maxLength must not be negative")
+	}
+	if entry.MaxLength > 0 && entry.MinLength > entry.MaxLength {
+		_, minNode := mappingValue(node, "minLength")
+		v.report(minNode, "minLength %d is greater than maxLength %d", entry.MinLength, entry.MaxLength)
+	}
+	if entry.Pattern != "" {
+		if _, err := regexp.Compile(entry.Pattern); err != nil {
+			_, patternNode := mappingValue(node, "pattern")
+			v.report(patternNode, "invalid pattern: %v", err)
+		}
+	}
+
+	_, suggestionsNode := mappingValue(node, "suggestions")
+	if entry.Suggestions.CommitPattern != "" {
+		if _, err := regexp.Compile(entry.Suggestions.CommitPattern); err != nil {
+			_, patternNode := mappingValue(suggestionsNode, "commitPattern")
+			v.report(patternNode, "invalid commitPattern: %v", err)
+		}
+	}
+	if entry.Suggestions.History < 0 {
+		_, historyNode := mappingValue(suggestionsNode, "history")
+		v.report(historyNode, "history must not be negative")
+	}
+	if entry.MultiLine && suggestionsNode != nil {
+		v.report(suggestionsNode, "suggestions are only supported for single-line entries")
+	}
+
+	if entry.SpellCheck != nil && entry.SpellCheck.Severity != "" && !contains(severities, entry.SpellCheck.Severity) {
+		_, spellCheckNode := mappingValue(node, "spellCheck")
+		_, severityNode := mappingValue(spellCheckNode, "severity")
+		v.report(severityNode, "invalid severity %q, expected one of: %s", entry.SpellCheck.Severity, strings.Join(severities, ", "))
+	}
+	if entry.Imperative.Severity != "" && !contains(severities, entry.Imperative.Severity) {
+		_, imperativeNode := mappingValue(node, "imperative")
+		_, severityNode := mappingValue(imperativeNode, "severity")
+		v.report(severityNode, "invalid severity %q, expected one of: %s", entry.Imperative.Severity, strings.Join(severities, ", "))
+	}
+}
+
+// validateChoiceEntry checks the choices and the default value of a choice entry and returns the values of the choices.
+func (v *validator) validateChoiceEntry(node *yaml.Node) map[string]bool {
+	var entry ChoiceEntry
+	if err := node.Decode(&entry); err != nil {
+		v.reportYAMLError(err, node.Line)
+		return nil
+	}
+
+	_, choicesNode := mappingValue(node, "choices")
+	if choicesNode == nil || len(entry.Choices) == 0 {
+		v.report(node, "choice entry %q has no choices", entry.Name)
+		return nil
+	}
+
+	values := make(map[string]bool)
+	for i, choice := range entry.Choices {
+		if values[choice.Value] {
+			_, valueNode := mappingValue(choicesNode.Content[i], "value")
+			if valueNode == nil {
+				valueNode = choicesNode.Content[i]
+			}
+			v.report(valueNode, "duplicate choice value %q", choice.Value)
+		}
+		values[choice.Value] = true
+	}
+
+	if entry.Default != "" && !values[entry.Default] {
+		_, defaultNode := mappingValue(node, "default")
+		v.report(defaultNode, "default %q is not one of the choices", entry.Default)
+	}
+	return values
+}
+
+// validateNumberEntry checks the range, step and default value of a number entry.
+func (v *validator) validateNumberEntry(node *yaml.Node) {
+	var entry NumberEntry
+	if err := node.Decode(&entry); err != nil {
+		v.reportYAMLError(err, node.Line)
+		return
+	}
+
+	if entry.Min != nil && entry.Max != nil && *entry.Min > *entry.Max {
+		_, minNode := mappingValue(node, "min")
+		v.report(minNode, "min %v is greater than max %v", *entry.Min, *entry.Max)
+		return
+	}
+	if entry.Step < 0 {
+		_, stepNode := mappingValue(node, "step")
+		v.report(stepNode, "step must not be negative")
+		return
+	}
+	if entry.Integer {
+		for _, key := range []string{"min", "max", "step"} {
+			if _, valueNode := mappingValue(node, key); valueNode != nil {
+				if value, err := strconv.ParseFloat(valueNode.Value, 64); err == nil && value != math.Trunc(value) {
+					v.report(valueNode, "%s must be a whole number for integer entries", key)
+				}
+			}
+		}
+	}
+	if _, defaultNode := mappingValue(node, "default"); defaultNode != nil && entry.Default != nil {
+		if _, err := entry.ParseValue(entry.FormatValue(*entry.Default)); err != nil || (entry.Integer && *entry.Default != math.Trunc(*entry.Default)) {
+			v.report(defaultNode, "default %v does not satisfy the constraints of entry %q", *entry.Default, entry.Name)
+		}
+	}
+}
+
+// validateTemplate checks that the template parses and only references known entries.
+func (v *validator) validateTemplate(node *yaml.Node, entries map[string]*entryInfo) {
+	tmpl, err := template.New("message").Parse(node.Value)
+	if err != nil {
+		line, column := node.Line, node.Column
+		message := err.Error()
+		if match := templateLinePattern.FindStringSubmatch(message); match != nil {
+			offset, _ := strconv.Atoi(match[1])
+			line, column = v.templatePosition(node, lineOffset(node.Value, offset))
+			message = match[2]
+		}
+		v.reportAt(line, column, "invalid template: %s", message)
+		return
+	}
+
+	for _, ref := range templateFieldRefs(tmpl.Tree.Root) {
+		if entries[ref.name] == nil {
+			line, column := v.templatePosition(node, int(ref.pos))
+			v.reportAt(line, column, "template references unknown entry %q", ref.name)
+		}
+	}
+}
+
+// validateComputed checks the templates of all computed entries and reports cycles between them.
+func (v *validator) validateComputed(entries map[string]*entryInfo) {
+	deps := make(map[string][]string)
+	var names []string
+	for name, info := range entries {
+		if info.template == nil {
+			continue
+		}
+		v.validateTemplate(info.template, entries)
+		tmpl, err := template.New(name).Parse(info.template.Value)
+		if err != nil {
+			continue // reported by validateTemplate
+		}
+		names = append(names, name)
+		deps[name] = nil
+		for _, ref := range templateFieldRefs(tmpl.Tree.Root) {
+			deps[name] = append(deps[name], ref.name)
+		}
+	}
+
+	sort.Strings(names)
+	if _, err := sortComputed(names, deps); err != nil {
+		var cycle *cycleError
+		if errors.As(err, &cycle) {
+			v.report(entries[cycle.names[0]].template, "%v", err)
+		}
+	}
+}
+
+// validateRules checks the rules section: the referenced field, the expressions and the message of every rule.
+func (v *validator) validateRules(root *yaml.Node, entries map[string]*entryInfo) {
+	_, rules := mappingValue(root, "rules")
+	if rules == nil || rules.Kind != yaml.SequenceNode {
+		return // a wrong type is reported when decoding
+	}
+
+	for _, rule := range rules.Content {
+		if _, fieldNode := mappingValue(rule, "field"); fieldNode != nil && entries[fieldNode.Value] == nil {
+			v.report(fieldNode, "rule refers to unknown entry %q", fieldNode.Value)
+		}
+		if _, messageNode := mappingValue(rule, "message"); messageNode == nil || messageNode.Value == "" {
+			v.report(rule, "rule has no message")
+		}
+
+		_, assertNode := mappingValue(rule, "assert")
+		if assertNode == nil || assertNode.Value == "" {
+			v.report(rule, "rule has no assert expression")
+		} else {
+			v.validateExpression(assertNode, entries)
+		}
+		if _, whenNode := mappingValue(rule, "when"); whenNode != nil && whenNode.Value != "" {
+			v.validateExpression(whenNode, entries)
+		}
+	}
+}
+
+// validateExpression checks that a rule expression parses and only references known entries.
+func (v *validator) validateExpression(node *yaml.Node, entries map[string]*entryInfo) {
+	tmpl, err := parseExpression("rule", node.Value)
+	if err != nil {
+		message := err.Error()
+		if match := templateLinePattern.FindStringSubmatch(message); match != nil {
+			message = match[2]
+		}
+		v.report(node, "invalid expression: %s", message)
+		return
+	}
+
+	for _, ref := range templateFieldRefs(tmpl.Tree.Root) {
+		if entries[ref.name] == nil {
+			line, column := v.templatePosition(node, int(ref.pos)-len(expressionPrefix))
+			v.reportAt(line, column, "expression references unknown entry %q", ref.name)
+		}
+	}
+}
+
+// validateSecrets checks the patterns and the entropy threshold of the secret scan.
+func (v *validator) validateSecrets(root *yaml.Node) {
+	_, secrets := mappingValue(root, "secrets")
+	if secrets == nil || secrets.Kind != yaml.MappingNode {
+		return // a wrong type is reported when decoding
+	}
+
+	if _, entropyNode := mappingValue(secrets, "entropy"); entropyNode != nil {
+		if entropy, err := strconv.ParseFloat(entropyNode.Value, 64); err == nil && entropy < 0 {
+			v.report(entropyNode, "entropy must not be negative")
+		}
+	}
+	if _, patterns := mappingValue(secrets, "patterns"); patterns != nil && patterns.Kind == yaml.SequenceNode {
+		for _, pattern := range patterns.Content {
+			if _, nameNode := mappingValue(pattern, "name"); nameNode == nil || nameNode.Value == "" {
+				v.report(pattern, "secret pattern has no name")
+			}
+			_, patternNode := mappingValue(pattern, "pattern")
+			if patternNode == nil || patternNode.Value == "" {
+				v.report(pattern, "secret pattern has no pattern")
+			} else if _, err := regexp.Compile(patternNode.Value); err != nil {
+				v.report(patternNode, "invalid secret pattern: %v", err)
+			}
+		}
+	}
+}
+
+// validateGuards checks the globs of the guards.
+func (v *validator) validateGuards(root *yaml.Node) {
+	_, guards := mappingValue(root, "guards")
+	if guards == nil || guards.Kind != yaml.MappingNode {
+		return // a wrong type is reported when decoding
+	}
+
+	checkGlob := func(node *yaml.Node) {
+		if node == nil || node.Value == "" {
+			return
+		}
+		if _, err := path.Match(strings.ReplaceAll(node.Value, "**", "*"), ""); err != nil {
+			v.report(node, "invalid glob %q: %v", node.Value, err)
+		}
+	}
+	if _, paths := mappingValue(guards, "forbiddenPaths"); paths != nil && paths.Kind == yaml.SequenceNode {
+		for _, node := range paths.Content {
+			checkGlob(node)
+		}
+	}
+	if _, companions := mappingValue(guards, "companions"); companions != nil && companions.Kind == yaml.SequenceNode {
+		for _, companion := range companions.Content {
+			for _, key := range []string{"when", "require"} {
+				_, node := mappingValue(companion, key)
+				if node == nil || node.Value == "" {
+					v.report(companion, "companion has no %s glob", key)
+				}
+				checkGlob(node)
+			}
+		}
+	}
+}
+
+// validateRevert checks that the revert values refer to existing entries and are valid templates.
+func (v *validator) validateRevert(root *yaml.Node, entries map[string]*entryInfo) {
+	_, revert := mappingValue(root, "revert")
+	_, values := mappingValue(revert, "values")
+	if values == nil || values.Kind != yaml.MappingNode {
+		return // a wrong type is reported when decoding
+	}
+
+	for i := 0; i+1 < len(values.Content); i += 2 {
+		key, value := values.Content[i], values.Content[i+1]
+		if entries[key.Value] == nil {
+			v.report(key, "revert value for unknown entry %q", key.Value)
+		}
+		if _, err := template.New(key.Value).Parse(value.Value); err != nil {
+			message := err.Error()
+			if match := templateLinePattern.FindStringSubmatch(message); match != nil {
+				message = match[2]
+			}
+			v.report(value, "invalid template: %s", message)
+		}
+	}
+}
+
+// validateBranches checks the branch overrides and the entries, choices and templates they refer to.
+func (v *validator) validateBranches(root *yaml.Node, entries map[string]*entryInfo) {
+	_, branches := mappingValue(root, "branches")
+	if branches == nil {
+		return
+	}
+	if branches.Kind != yaml.SequenceNode {
+		v.report(branches, "branches must be a list")
+		return
+	}
+
+	for _, node := range branches.Content {
+		if node.Kind != yaml.MappingNode {
+			v.report(node, "branch override must be a mapping")
+			continue
+		}
+		v.decodeFields(node, reflect.TypeOf(BranchOverride{}), "entries")
+
+		_, matchNode := mappingValue(node, "match")
+		if matchNode == nil || matchNode.Value == "" {
+			v.report(node, "branch override is missing a match pattern")
+		} else if _, err := path.Match(matchNode.Value, ""); err != nil {
+			v.report(matchNode, "invalid branch pattern %q: %v", matchNode.Value, err)
+		}
+
+		// Entries of the override are added to (or replace) the regular entries
+		available := make(map[string]*entryInfo)
+		for name, info := range entries {
+			available[name] = info
+		}
+		if _, entriesNode := mappingValue(node, "entries"); entriesNode != nil {
+			added := v.validateEntryList(entriesNode)
+			for name, info := range added {
+				available[name] = info
+			}
+			for _, info := range added {
+				if info.template != nil {
+					v.validateTemplate(info.template, available)
+				}
+			}
+		}
+
+		if _, hideNode := mappingValue(node, "hide"); hideNode != nil && hideNode.Kind == yaml.SequenceNode {
+			for _, item := range hideNode.Content {
+				if available[item.Value] == nil {
+					v.report(item, "hide references unknown entry %q", item.Value)
+				}
+			}
+		}
+
+		if _, choicesNode := mappingValue(node, "choices"); choicesNode != nil && choicesNode.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(choicesNode.Content); i += 2 {
+				key, values := choicesNode.Content[i], choicesNode.Content[i+1]
+				info := available[key.Value]
+				if info == nil {
+					v.report(key, "choices references unknown entry %q", key.Value)
+					continue
+				}
+				if info.typ != "Choice" {
+					v.report(key, "choices references entry %q which is not a choice", key.Value)
+					continue
+				}
+				if values.Kind != yaml.SequenceNode || info.choices == nil {
+					continue
+				}
+				for _, item := range values.Content {
+					if !info.choices[item.Value] {
+						v.report(item, "entry %q has no choice %q", key.Value, item.Value)
+					}
+				}
+			}
+		}
+
+		if _, templateNode := mappingValue(node, "template"); templateNode != nil && templateNode.Value != "" {
+			v.validateTemplate(templateNode, available)
+		}
+	}
+}
+
+// decodeFields decodes every field of the mapping node individually into the matching field of the given struct type,
+// so that type mismatches are reported at the position of the offending value. Keys listed in skip are ignored.
+// It returns true if all fields could be decoded.
+func (v *validator) decodeFields(node *yaml.Node, typ reflect.Type, skip ...string) bool {
+	fields := yamlFields(typ)
+	ok := true
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field, known := fields[key.Value]
+		if !known || contains(skip, key.Value) {
+			continue
+		}
+		target := reflect.New(field.Type)
+		if err := value.Decode(target.Interface()); err != nil {
+			v.reportDecodeError(err, value)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// reportYAMLError converts an error returned by the yaml package into problems.
+// The line is taken from the error message if present, otherwise the fallback line is used.
+func (v *validator) reportYAMLError(err error, fallbackLine int) {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	for _, message := range messages {
+		if match := yamlLinePattern.FindStringSubmatch(message); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			v.reportAt(line, 1, "%s", match[2])
+			continue
+		}
+		v.reportAt(fallbackLine, 1, "%s", strings.TrimPrefix(message, "yaml: "))
+	}
+}
+
+// reportDecodeError reports an error returned while decoding a single value at the position of that value.
+func (v *validator) reportDecodeError(err error, node *yaml.Node) {
+	var posErr *nodeError
+	if errors.As(err, &posErr) {
+		v.reportAt(posErr.line, posErr.column, "%s", posErr.message)
+		return
+	}
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	for _, message := range messages {
+		if match := yamlLinePattern.FindStringSubmatch(message); match != nil {
+			message = match[2]
+		}
+		v.report(node, "%s", message)
+	}
+}
+
+// templatePosition maps a byte offset within the template string to a position in the configuration file.
+func (v *validator) templatePosition(node *yaml.Node, offset int) (int, int) {
+	if offset > len(node.Value) {
+		offset = len(node.Value)
+	}
+	before := node.Value[:offset]
+	line := strings.Count(before, "\n")
+	column := offset - (strings.LastIndex(before, "\n") + 1) + 1
+
+	switch {
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		// Block scalars start on the line after the indicator and are indented
+		indent := 0
+		if node.Line < len(v.source) {
+			content := v.source[node.Line]
+			indent = len(content) - len(strings.TrimLeft(content, " "))
+		}
+		return node.Line + 1 + line, indent + column
+	case line > 0:
+		return node.Line + line, column
+	case node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+		return node.Line, node.Column + column
+	default:
+		return node.Line, node.Column + column - 1
+	}
+}
+
+// fieldRef is a reference to a top-level field (e.g. {{ .header }}) within a template.
+type fieldRef struct {
+	name string    // The name of the referenced field
+	pos  parse.Pos // The byte offset of the reference within the template
+}
+
+// templateFieldRefs collects all references to top-level fields within a parsed template.
+// Fields inside range and with blocks are skipped as they refer to a different context.
+func templateFieldRefs(node parse.Node) []fieldRef {
+	var refs []fieldRef
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			refs = append(refs, fieldRef{name: n.Ident[0], pos: n.Pos})
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				refs = append(refs, fieldRef{name: n.Ident[1], pos: n.Pos})
+			}
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+	walk(node)
+	return refs
+}
+
+// mappingValue looks up a key in a mapping node and returns the key and value nodes, or nil if the key is not present.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// yamlFields returns the fields of a struct type keyed by their yaml name. Fields tagged with "-" are omitted.
+func yamlFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// lineOffset returns the byte offset of the given 1-based line within the text.
+func lineOffset(text string, line int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	return offset
+}
+
+// contains reports whether the list contains the given value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
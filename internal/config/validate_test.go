@@ -0,0 +1,219 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeConfig writes a configuration file into a temporary directory and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".commity.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "valid",
+			config: `entries:
+  - type: Text
+    name: subject
+    label: Subject
+template: "{{ .subject }}"
+`,
+		},
+		{
+			name:   "no entries and template",
+			config: "entries: []\n",
+			want:   []string{"1:1: no template defined", "1:10: no entries defined"},
+		},
+		{
+			name: "entry problems",
+			config: `entries:
+  - type: Text
+    name: subject
+    minLength: 10
+    maxLength: 5
+  - type: Text
+    name: subject
+    pattern: "("
+  - name: body
+  - type: Choice
+    name: kind
+    choices:
+      - value: feat
+      - value: feat
+    default: fix
+template: "{{ .subject }}"
+`,
+			want: []string{
+				`4:16: minLength 10 is greater than maxLength 5`,
+				`7:11: duplicate entry name "subject" (first defined at line 3)`,
+				"8:14: invalid pattern: error parsing regexp: missing closing ): `(`",
+				`9:5: entry is missing the type field`,
+				`14:16: duplicate choice value "feat"`,
+				`15:14: default "fix" is not one of the choices`,
+			},
+		},
+		{
+			name: "invalid deny pattern",
+			config: `entries:
+  - type: Text
+    name: subject
+    denyPatterns: ["JIRA-\\d+", "("]
+template: "{{ .subject }}"
+`,
+			want: []string{
+				"4:33: invalid pattern: error parsing regexp: missing closing ): `(`",
+			},
+		},
+		{
+			name: "number problems",
+			config: `entries:
+  - type: Number
+    name: points
+    min: 5
+    max: 1
+  - type: Number
+    name: estimate
+    integer: true
+    max: 8.5
+    step: -1
+  - type: Number
+    name: hours
+    integer: true
+    max: 8.5
+  - type: Number
+    name: size
+    min: 1
+    step: 2
+    default: 4
+template: "{{ .points }}"
+`,
+			want: []string{
+				`4:10: min 5 is greater than max 1`,
+				`10:11: step must not be negative`,
+				`14:10: max must be a whole number for integer entries`,
+				`19:14: default 4 does not satisfy the constraints of entry "size"`,
+			},
+		},
+		{
+			name: "rules reference unknown entries",
+			config: `entries:
+  - type: Text
+    name: subject
+template: "{{ .subject }}"
+rules:
+  - assert: "eq .nope 1"
+    message: Nope
+`,
+			want: []string{`6:17: expression references unknown entry "nope"`},
+		},
+		{
+			name: "invalid guards",
+			config: `entries:
+  - type: Text
+    name: subject
+template: "{{ .subject }}"
+guards:
+  maxFileSize: huge
+`,
+			want: []string{`6:16: invalid file size "huge" (expected e.g. 500KB or 1MB)`},
+		},
+		{
+			name: "invalid store scope and cache identity",
+			config: `entries:
+  - type: Text
+    name: scope
+    store: true
+    storeScope: Branch
+template: "{{ .scope }}"
+cache:
+  identity: url
+`,
+			want: []string{
+				`5:17: invalid store scope "Branch", expected one of: repository, branch, worktree, global`,
+				`8:13: invalid cache identity "url", expected one of: path, remote, root`,
+			},
+		},
+		{
+			name: "type mismatch",
+			config: `entries:
+  - type: Text
+    name: subject
+    maxLength: long
+template: "{{ .subject }}"
+`,
+			want: []string{"4:16: cannot unmarshal !!str `long` into int"},
+		},
+		{
+			name: "template references unknown entry",
+			config: `entries:
+  - type: Text
+    name: subject
+template: "{{ .subject }} {{ .missing }}"
+`,
+			want: []string{`4:30: template references unknown entry "missing"`},
+		},
+		{
+			name: "unknown field",
+			config: `entries:
+  - type: Text
+    name: subject
+template: "{{ .subject }}"
+templat: x
+`,
+			want: []string{`5:1: unknown field "templat" in configuration (did you mean "template"?)`},
+		},
+		{
+			name: "unknown field without strict mode",
+			config: `strict: false
+entries:
+  - type: Text
+    name: subject
+    labl: Subject
+template: "{{ .subject }}"
+`,
+		},
+		{
+			name: "invalid template",
+			config: `entries:
+  - type: Text
+    name: subject
+template: "{{ .subject }"
+`,
+			want: []string{`4:12: invalid template: unexpected "}" in operand`},
+		},
+		{
+			name:   "newer version",
+			config: "version: 2\nentries: []\n",
+			want:   []string{"1:1: configuration version 2 is newer than the supported version 1, please update commity"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems, err := Validate(writeConfig(t, test.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, problem := range problems {
+				got = append(got, problem.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Validate() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
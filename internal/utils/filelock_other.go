@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package utils
+
+import "os"
+
+// tryLockFile always succeeds, as advisory locks are not supported on this platform.
+func tryLockFile(file *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile does nothing, as advisory locks are not supported on this platform.
+func unlockFile(file *os.File) {}
@@ -0,0 +1,81 @@
+//go:build unix || windows
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFileMergesConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "values")
+	lock := filepath.Join(dir, ".lock")
+
+	// Every writer adds its value with a read-modify-write under the lock, none may be lost
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			unlock, err := LockFile(lock, 10*time.Second)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer unlock()
+
+			data, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				errs <- err
+				return
+			}
+			data = append(data, fmt.Sprintf("%02d\n", i)...)
+			if err := WriteFileAtomic(path, data, 0644); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := strings.Fields(string(data))
+	sort.Strings(values)
+	if len(values) != writers {
+		t.Fatalf("got %d values, want %d: %v", len(values), writers, values)
+	}
+	for i, value := range values {
+		if value != fmt.Sprintf("%02d", i) {
+			t.Errorf("values = %v, missing %02d", values, i)
+			break
+		}
+	}
+}
+
+func TestLockFileTimeout(t *testing.T) {
+	lock := filepath.Join(t.TempDir(), ".lock")
+	unlock, err := LockFile(lock, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	if _, err := LockFile(lock, 100*time.Millisecond); err == nil {
+		t.Error("LockFile() acquired a lock held by another caller")
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockPollInterval is the time between two attempts to acquire a lock held by another process.
+const lockPollInterval = 50 * time.Millisecond
+
+// WriteFileAtomic writes data to a file by writing a temporary file in the same directory and renaming it,
+// so readers never see a partially written file.
+//
+// Arguments:
+// - path: The path of the file.
+// - data: The content of the file.
+// - perm: The permissions of the file.
+//
+// Returns:
+// - An error if the file cannot be written, in which case the original file is left unchanged.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// Remove the temporary file on failure, after a successful rename this is a no-op
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LockFile acquires an exclusive advisory lock on the given file, creating it if it doesn't exist.
+// Other processes calling LockFile for the same file wait until the lock is released.
+//
+// Arguments:
+// - path: The path of the lock file.
+// - timeout: How long to wait for a lock held by another process.
+//
+// Returns:
+// - A function releasing the lock.
+// - An error if the lock file cannot be opened or the lock is not acquired within the timeout.
+func LockFile(path string, timeout time.Duration) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out waiting for the lock on %s", path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
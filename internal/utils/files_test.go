@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.yaml")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new\n" {
+		t.Errorf("content = %q, want %q", data, "new\n")
+	}
+
+	// Only the written file remains, the temporary file was renamed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "cache.yaml" {
+		t.Errorf("directory contains %v, want only cache.yaml", entries)
+	}
+
+	// A failed write leaves no temporary file behind
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "cache.yaml"), []byte("x"), 0644); err == nil {
+		t.Error("WriteFileAtomic() into a missing directory succeeded")
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, "cache.yaml", "x"), []byte("x"), 0644); err == nil {
+		t.Error("WriteFileAtomic() below a file succeeded")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory contains %v after failed writes, want only cache.yaml", entries)
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+// GuardViolation is a staged file violating one of the guards.
+type GuardViolation struct {
+	Path    string // The path of the staged file; unstaging it resolves the violation
+	Message string // The description of the violation
+}
+
+// String formats the violation as "path: message".
+func (v GuardViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// CheckGuards checks the staged changes against the guards of the configuration.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - guards: The guards to check.
+// - paths: The files committed, nil if all staged files are committed. Other staged files are neither checked nor satisfy a companion.
+//
+// Returns:
+// - All violations ordered by path.
+// - An error if the staged files cannot be read.
+func CheckGuards(repoPath string, guards config.Guards, paths []string) ([]GuardViolation, error) {
+	// Comparing the index with HEAD reads the whole tree, which is skipped if there is nothing to check
+	if guards.MaxFileSize == 0 && len(guards.ForbiddenPaths) == 0 && len(guards.Companions) == 0 {
+		return nil, nil
+	}
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	changes, err := stagedChanges(repo)
+	if err != nil {
+		return nil, err
+	}
+	if paths != nil {
+		selected := make(map[string]bool)
+		for _, path := range paths {
+			selected[path] = true
+		}
+		var committed []StagedChange
+		for _, change := range changes {
+			if selected[change.Path] {
+				committed = append(committed, change)
+			}
+		}
+		changes = committed
+	}
+
+	var violations []GuardViolation
+	for _, change := range changes {
+		if !change.Hash.IsZero() {
+			for _, glob := range guards.ForbiddenPaths {
+				if config.MatchGlob(glob, change.Path) {
+					violations = append(violations, GuardViolation{Path: change.Path, Message: fmt.Sprintf("matches forbidden path %q", glob)})
+					break
+				}
+			}
+
+			if guards.MaxFileSize > 0 {
+				blob, err := repo.BlobObject(change.Hash)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read blob %s: %w", change.Hash, err)
+				}
+				if size := config.FileSize(blob.Size); size > guards.MaxFileSize {
+					violations = append(violations, GuardViolation{Path: change.Path, Message: fmt.Sprintf("is %s, exceeding the limit of %s", size, guards.MaxFileSize)})
+				}
+			}
+		}
+
+		for _, companion := range guards.Companions {
+			if !config.MatchGlob(companion.When, change.Path) {
+				continue
+			}
+			found := false
+			for _, other := range changes {
+				if config.MatchGlob(companion.Require, other.Path) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				violations = append(violations, GuardViolation{Path: change.Path, Message: fmt.Sprintf("requires a staged change of %s", companion.Require)})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// UnstageFiles removes the staged changes of the given files from the index, leaving the working tree untouched.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - files: The slash-separated paths of the files relative to the repository root.
+//
+// Returns:
+// - An error if the index cannot be updated.
+func UnstageFiles(repoPath string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open Git repository: %w", err)
+	}
+
+	// Without commits there is nothing to restore, so the files are removed from the index
+	if _, err := repo.Head(); errors.Is(err, plumbing.ErrReferenceNotFound) {
+		idx, err := repo.Storer.Index()
+		if err != nil {
+			return fmt.Errorf("failed to read Git index: %w", err)
+		}
+		for _, file := range files {
+			if _, err := idx.Remove(file); err != nil {
+				return fmt.Errorf("failed to unstage %s: %w", file, err)
+			}
+		}
+		return repo.Storer.SetIndex(idx)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get Git worktree: %w", err)
+	}
+	if err := worktree.Restore(&git.RestoreOptions{Staged: true, Files: files}); err != nil {
+		return fmt.Errorf("failed to unstage files: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+// stageFiles creates a repository in a temporary directory with the committed files in HEAD and the staged files
+// in the index. A staged file with empty content is staged as deleted.
+func stageFiles(t *testing.T, committed map[string]string, staged map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(files map[string]string) {
+		for name, content := range files {
+			file := filepath.Join(dir, filepath.FromSlash(name))
+			if content == "" {
+				if _, err := worktree.Remove(name); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := worktree.Add(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if len(committed) > 0 {
+		write(committed)
+		signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+		if _, err := worktree.Commit("initial", &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(staged)
+	return dir
+}
+
+func TestCheckGuards(t *testing.T) {
+	committed := map[string]string{"go.mod": "module a\n", "go.sum": "a\n", "secrets.env": "A=1\n"}
+
+	tests := []struct {
+		name   string
+		guards config.Guards
+		staged map[string]string
+		paths  []string
+		want   []string
+	}{
+		{
+			name:   "file size",
+			guards: config.Guards{MaxFileSize: 10},
+			staged: map[string]string{"small.txt": "tiny", "big.bin": strings.Repeat("x", 2048)},
+			want:   []string{"big.bin: is 2KB, exceeding the limit of 10B"},
+		},
+		{
+			name:   "forbidden paths",
+			guards: config.Guards{ForbiddenPaths: []string{"*.env", "dist/**"}},
+			staged: map[string]string{"config/.env": "A=1\n", "dist/app/main.js": "x", "src/dist.go": "x"},
+			want:   []string{"config/.env: matches forbidden path \"*.env\"", "dist/app/main.js: matches forbidden path \"dist/**\""},
+		},
+		{
+			name:   "deleting a forbidden path",
+			guards: config.Guards{ForbiddenPaths: []string{"*.env"}, MaxFileSize: 1},
+			staged: map[string]string{"secrets.env": ""},
+		},
+		{
+			name:   "missing companion",
+			guards: config.Guards{Companions: []config.Companion{{When: "go.mod", Require: "go.sum"}}},
+			staged: map[string]string{"go.mod": "module b\n"},
+			want:   []string{"go.mod: requires a staged change of go.sum"},
+		},
+		{
+			name:   "staged companion",
+			guards: config.Guards{Companions: []config.Companion{{When: "go.mod", Require: "go.sum"}}},
+			staged: map[string]string{"go.mod": "module b\n", "go.sum": "b\n"},
+		},
+		{
+			name:   "companion staged but not committed",
+			guards: config.Guards{Companions: []config.Companion{{When: "go.mod", Require: "go.sum"}}},
+			staged: map[string]string{"go.mod": "module b\n", "go.sum": "b\n"},
+			paths:  []string{"go.mod"},
+			want:   []string{"go.mod: requires a staged change of go.sum"},
+		},
+		{
+			name:   "only committed files are checked",
+			guards: config.Guards{ForbiddenPaths: []string{"*.env"}, Companions: []config.Companion{{When: "go.mod", Require: "go.sum"}}},
+			staged: map[string]string{"go.mod": "module b\n", "config/.env": "A=1\n", "main.go": "package main\n"},
+			paths:  []string{"main.go"},
+		},
+		{
+			name:   "companion not triggered",
+			guards: config.Guards{Companions: []config.Companion{{When: "go.mod", Require: "go.sum"}}},
+			staged: map[string]string{"main.go": "package main\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := CheckGuards(stageFiles(t, committed, test.staged), test.guards, test.paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, violation := range violations {
+				got = append(got, violation.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("CheckGuards() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnstageFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed map[string]string
+	}{
+		{name: "with commits", committed: map[string]string{"README.md": "readme\n"}},
+		{name: "without commits"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repoPath := stageFiles(t, test.committed, map[string]string{"a.txt": "a", "b.txt": "b"})
+			if err := UnstageFiles(repoPath, []string{"a.txt"}); err != nil {
+				t.Fatal(err)
+			}
+			changes, err := GetStagedChanges(repoPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var paths []string
+			for _, change := range changes {
+				paths = append(paths, change.Path)
+			}
+			if want := []string{"b.txt"}; !reflect.DeepEqual(paths, want) {
+				t.Errorf("staged files = %v, want %v", paths, want)
+			}
+			if _, err := os.Stat(filepath.Join(repoPath, "a.txt")); err != nil {
+				t.Errorf("unstaged file was removed from the working tree: %v", err)
+			}
+		})
+	}
+}
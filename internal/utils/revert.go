@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RevertCommit applies the inverse of a commit to the working tree and stages it, without committing.
+// If a file changed by the commit has been modified since, the revert is left to the git binary,
+// which can merge the changes. Nothing else may be staged, as it would be committed with the revert.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - rev: The revision of the commit to revert (e.g. a hash or "HEAD~2").
+//
+// Returns:
+// - The reverted commit.
+// - An error if the revision cannot be resolved, the commit is a merge commit, other changes are staged,
+// local changes would be overwritten, or the revert results in conflicts.
+func RevertCommit(repoPath string, rev string) (*object.Commit, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", rev, err)
+	}
+	if commit.NumParents() > 1 {
+		return nil, fmt.Errorf("reverting merge commits is not supported")
+	}
+
+	changes, err := commitChanges(commit)
+	if err != nil {
+		return nil, err
+	}
+	headFiles, err := headBlobs(repo)
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Git worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Git status: %w", err)
+	}
+
+	for path, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			return nil, fmt.Errorf("%s is staged, commit or unstage it before reverting", path)
+		}
+	}
+
+	// The revert can only be applied directly if every file is still in the state the commit left it in
+	direct := true
+	for _, change := range changes {
+		path := changePath(change)
+		if fileStatus, ok := status[path]; ok && (fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified) {
+			return nil, fmt.Errorf("local changes to %s would be overwritten by the revert", path)
+		}
+
+		var current plumbing.Hash
+		if file := headFiles[path]; file != nil {
+			current = file.Hash
+		}
+		if current != change.To.TreeEntry.Hash || !regularFile(change.From.TreeEntry.Mode) || !regularFile(change.To.TreeEntry.Mode) {
+			direct = false
+		}
+	}
+	if !direct {
+		return commit, revertWithGit(repoPath, commit.Hash)
+	}
+
+	for _, change := range changes {
+		path := changePath(change)
+		if change.From.Name == "" {
+			// Added by the commit
+			if _, err := worktree.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			continue
+		}
+
+		content, err := readBlob(repo, change.From.TreeEntry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		perm := os.FileMode(0o644)
+		if change.From.TreeEntry.Mode == filemode.Executable {
+			perm = 0o755
+		}
+		file := filepath.Join(repoPath, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		if err := os.WriteFile(file, content, perm); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		if err := os.Chmod(file, perm); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			return nil, fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	return commit, nil
+}
+
+// commitChanges returns the changes a commit made compared to its parent.
+func commitChanges(commit *object.Commit) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", commit.Hash, err)
+	}
+	parentTree := &object.Tree{}
+	if commit.NumParents() == 1 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with its parent: %w", commit.Hash, err)
+	}
+	return changes, nil
+}
+
+// changePath returns the path of the file affected by a change.
+func changePath(change *object.Change) string {
+	if change.To.Name != "" {
+		return change.To.Name
+	}
+	return change.From.Name
+}
+
+// regularFile reports whether the mode is a regular or executable file, or empty for a missing file.
+func regularFile(mode filemode.FileMode) bool {
+	return mode == filemode.Empty || mode == filemode.Regular || mode == filemode.Executable || mode == filemode.Deprecated
+}
+
+// revertWithGit reverts a commit with the git binary without committing, which merges modified files.
+// A revert that fails, e.g. because of conflicts, is aborted, so the working tree and index are left as they were.
+func revertWithGit(repoPath string, hash plumbing.Hash) error {
+	cmd := exec.Command("git", "-C", repoPath, "revert", "--no-commit", hash.String())
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(out.String())
+		if repo, openErr := git.PlainOpen(repoPath); openErr == nil && reverting(repo) {
+			if abortErr := runGitRevert(repoPath, "--abort"); abortErr != nil {
+				return fmt.Errorf("git revert failed:\n%s\n%w", output, abortErr)
+			}
+			return fmt.Errorf("git revert failed and was aborted, the commit cannot be reverted without resolving conflicts:\n%s", output)
+		}
+		return fmt.Errorf("git revert failed:\n%s", output)
+	}
+	return nil
+}
+
+// UndoRevert restores the state before RevertCommit, if the revert was not committed.
+// A revert done by the git binary is aborted, otherwise the files changed by the commit are reset to HEAD
+// in the index and the working tree.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - commit: The reverted commit.
+//
+// Returns:
+// - An error if the files cannot be restored.
+func UndoRevert(repoPath string, commit *object.Commit) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	if reverting(repo) {
+		return runGitRevert(repoPath, "--abort")
+	}
+
+	changes, err := commitChanges(commit)
+	if err != nil {
+		return err
+	}
+	headFiles, err := headBlobs(repo)
+	if err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get Git worktree: %w", err)
+	}
+
+	for _, change := range changes {
+		path := changePath(change)
+		file := filepath.Join(repoPath, filepath.FromSlash(path))
+		head := headFiles[path]
+		if head == nil {
+			// Restored by the revert after the commit deleted it
+			if _, err := worktree.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			continue
+		}
+
+		content, err := readBlob(repo, head.Hash)
+		if err != nil {
+			return err
+		}
+		perm := os.FileMode(0o644)
+		if head.Mode == filemode.Executable {
+			perm = 0o755
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		if err := os.WriteFile(file, content, perm); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		if err := os.Chmod(file, perm); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// FinishRevert ends a revert done by the git binary after it was committed, so git no longer reports it
+// as in progress. Reverts applied directly leave no state behind.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+//
+// Returns:
+// - An error if the revert state cannot be removed.
+func FinishRevert(repoPath string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	if !reverting(repo) {
+		return nil
+	}
+	return runGitRevert(repoPath, "--quit")
+}
+
+// reverting reports whether a revert by the git binary is in progress.
+func reverting(repo *git.Repository) bool {
+	_, err := repo.Reference(plumbing.ReferenceName("REVERT_HEAD"), false)
+	return err == nil
+}
+
+// runGitRevert runs git revert with the given option, e.g. --abort.
+func runGitRevert(repoPath string, option string) error {
+	cmd := exec.Command("git", "-C", repoPath, "revert", option)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git revert %s failed:\n%s", option, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
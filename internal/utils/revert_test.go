@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFiles writes, stages and commits the given files in the repository. A file with empty content is deleted.
+func commitFiles(t *testing.T, repoPath string, files map[string]string) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if content == "" {
+			if _, err := worktree.Remove(name); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	if _, err := worktree.Commit("change", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// workingFiles returns the content of the given files in the working tree, omitting missing files.
+func workingFiles(t *testing.T, repoPath string, names ...string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(repoPath, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = string(content)
+	}
+	return files
+}
+
+// assertRevertState checks whether a revert of the git binary is in progress and whether the repository is clean.
+func assertRevertState(t *testing.T, repoPath string, wantReverting bool, wantClean bool) {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reverting(repo); got != wantReverting {
+		t.Errorf("reverting = %v, want %v", got, wantReverting)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.IsClean() != wantClean {
+		t.Errorf("clean = %v, want %v, status:\n%s", status.IsClean(), wantClean, status)
+	}
+}
+
+// requireGit skips the test if the git binary is not available.
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+}
+
+func TestRevertCommitDirect(t *testing.T) {
+	base := map[string]string{"modified.txt": "v1\n", "deleted.txt": "deleted\n", "kept.txt": "kept\n"}
+	repoPath := stageFiles(t, base, nil)
+	commitFiles(t, repoPath, map[string]string{"added.txt": "added\n", "modified.txt": "v2\n", "deleted.txt": ""})
+
+	commit, err := RevertCommit(repoPath, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Message != "change" {
+		t.Errorf("RevertCommit() commit = %q, want the reverted commit", commit.Message)
+	}
+
+	got := workingFiles(t, repoPath, "added.txt", "modified.txt", "deleted.txt", "kept.txt")
+	if !reflect.DeepEqual(got, base) {
+		t.Errorf("working tree = %v, want %v", got, base)
+	}
+	changes, err := GetStagedChanges(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	if want := []string{"added.txt", "deleted.txt", "modified.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("staged files = %v, want %v", paths, want)
+	}
+	assertRevertState(t, repoPath, false, false)
+
+	// Undoing the revert restores the content of HEAD
+	if err := UndoRevert(repoPath, commit); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"added.txt": "added\n", "modified.txt": "v2\n", "kept.txt": "kept\n"}
+	if got := workingFiles(t, repoPath, "added.txt", "modified.txt", "deleted.txt", "kept.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("working tree after undo = %v, want %v", got, want)
+	}
+	assertRevertState(t, repoPath, false, true)
+}
+
+func TestRevertCommitWithGit(t *testing.T) {
+	requireGit(t)
+	repoPath := stageFiles(t, map[string]string{"a.txt": "one\ntwo\nthree\nfour\nfive\n"}, nil)
+	commitFiles(t, repoPath, map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nfive\n"})
+	commitFiles(t, repoPath, map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nFIVE\n"})
+
+	// The file was modified after the reverted commit, so git merges the revert
+	commit, err := RevertCommit(repoPath, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a.txt": "one\ntwo\nthree\nfour\nFIVE\n"}
+	if got := workingFiles(t, repoPath, "a.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("working tree = %v, want %v", got, want)
+	}
+	assertRevertState(t, repoPath, true, false)
+
+	// Undoing the revert aborts it
+	if err := UndoRevert(repoPath, commit); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]string{"a.txt": "ONE\ntwo\nthree\nfour\nFIVE\n"}
+	if got := workingFiles(t, repoPath, "a.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("working tree after undo = %v, want %v", got, want)
+	}
+	assertRevertState(t, repoPath, false, true)
+}
+
+func TestRevertCommitConflict(t *testing.T) {
+	requireGit(t)
+	repoPath := stageFiles(t, map[string]string{"a.txt": "one\n"}, nil)
+	commitFiles(t, repoPath, map[string]string{"a.txt": "two\n"})
+	commitFiles(t, repoPath, map[string]string{"a.txt": "three\n"})
+
+	if _, err := RevertCommit(repoPath, "HEAD~1"); err == nil {
+		t.Fatal("RevertCommit() error = nil, want a conflict")
+	}
+	want := map[string]string{"a.txt": "three\n"}
+	if got := workingFiles(t, repoPath, "a.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("working tree = %v, want %v", got, want)
+	}
+	assertRevertState(t, repoPath, false, true)
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/michaelrampl/commity/internal/config"
+)
+
+// maxScannedBlobSize is the size up to which staged blobs are scanned for secrets.
+const maxScannedBlobSize = 5 << 20
+
+// SecretFinding is a possible secret in a line added by the staged changes.
+type SecretFinding struct {
+	Path string // The path of the file
+	Line int    // The line number within the staged file
+	Rule string // The name of the rule that matched
+}
+
+// String formats the finding as "path:line: rule".
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Rule)
+}
+
+// ScanStagedSecrets scans the lines added by the staged changes for secrets.
+// Lines that are already present in the HEAD version of a file are skipped, as are binary and very large files.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - scan: The configuration of the scan.
+// - skip: The exact paths of files that are not scanned, unlike the globs of scan.Ignore.
+//
+// Returns:
+// - All findings ordered by path and line.
+// - An error if a pattern is invalid or the staged files cannot be read.
+func ScanStagedSecrets(repoPath string, scan *config.SecretScan, skip []string) ([]SecretFinding, error) {
+	scanner, err := config.NewSecretScanner(scan)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret pattern: %w", err)
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	changes, err := stagedChanges(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]bool)
+	for _, file := range skip {
+		skipped[file] = true
+	}
+
+	var findings []SecretFinding
+	for _, change := range changes {
+		if change.Hash.IsZero() || skipped[change.Path] || scanner.Ignored(change.Path) {
+			continue
+		}
+		blob, err := repo.BlobObject(change.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blob %s: %w", change.Hash, err)
+		}
+		if blob.Size > maxScannedBlobSize {
+			continue
+		}
+
+		content, err := readBlob(repo, change.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(content, 0) >= 0 {
+			continue // binary file
+		}
+		old, err := readBlob(repo, change.OldHash)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]bool)
+		for _, line := range strings.Split(string(old), "\n") {
+			existing[line] = true
+		}
+
+		for i, line := range strings.Split(string(content), "\n") {
+			if existing[line] {
+				continue
+			}
+			for _, rule := range scanner.ScanLine(change.Path, line) {
+				findings = append(findings, SecretFinding{Path: change.Path, Line: i + 1, Rule: rule})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// AppendTrailer adds a git trailer (e.g. "Signed-off-by: ...") to the end of a commit message.
+// If the message already ends with a block of trailers, the trailer is added to it, otherwise a new block is started.
+//
+// Arguments:
+// - message: The commit message.
+// - key: The key of the trailer.
+// - value: The value of the trailer, line breaks are replaced with spaces.
+//
+// Returns:
+// - The message including the trailer, ending with a line break.
+func AppendTrailer(message string, key string, value string) string {
+	message = strings.TrimRight(message, "\n")
+	trailer := key + ": " + strings.Join(strings.Fields(value), " ")
+
+	paragraphs := strings.Split(message, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	isTrailerBlock := len(paragraphs) > 1
+	for _, line := range strings.Split(last, "\n") {
+		if k, _, ok := strings.Cut(line, ": "); !ok || k == "" || strings.ContainsAny(k, " \t") {
+			isTrailerBlock = false
+		}
+	}
+
+	if isTrailerBlock {
+		return message + "\n" + trailer + "\n"
+	}
+	return message + "\n\n" + trailer + "\n"
+}
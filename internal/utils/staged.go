@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// StagedChange describes a file whose staged content differs from HEAD.
+type StagedChange struct {
+	Path    string        // The slash-separated path of the file relative to the repository root
+	Hash    plumbing.Hash // The staged blob, zero if the file is deleted
+	OldHash plumbing.Hash // The blob in HEAD, zero if the file is added
+}
+
+// GetStagedChanges returns all files whose staged content differs from HEAD, sorted by path.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+//
+// Returns:
+// - The staged changes.
+// - An error if the repository, its index or HEAD cannot be read.
+func GetStagedChanges(repoPath string) ([]StagedChange, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	return stagedChanges(repo)
+}
+
+// stagedChanges compares the index of the repository with the tree of HEAD.
+func stagedChanges(repo *git.Repository) ([]StagedChange, error) {
+	headFiles, err := headBlobs(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Git index: %w", err)
+	}
+
+	var changes []StagedChange
+	staged := make(map[string]bool)
+	for _, entry := range idx.Entries {
+		// Regular entries have stage 0 (go-git's index.Merged constant is 1), others are unresolved conflicts
+		if entry.Stage != 0 {
+			continue
+		}
+		staged[entry.Name] = true
+		var old plumbing.Hash
+		if file := headFiles[entry.Name]; file != nil {
+			old = file.Hash
+		}
+		if old != entry.Hash {
+			changes = append(changes, StagedChange{Path: entry.Name, Hash: entry.Hash, OldHash: old})
+		}
+	}
+	for name, file := range headFiles {
+		if !staged[name] {
+			changes = append(changes, StagedChange{Path: name, OldHash: file.Hash})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// headBlobs returns all files in the tree of HEAD by path.
+// For a repository without commits an empty map is returned.
+func headBlobs(repo *git.Repository) (map[string]*object.File, error) {
+	files := make(map[string]*object.File)
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
+	}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		files[file.Name] = file
+		return nil
+	})
+	return files, err
+}
+
+// readBlob returns the content of a blob, or nil for the zero hash.
+func readBlob(repo *git.Repository, hash plumbing.Hash) ([]byte, error) {
+	if hash.IsZero() {
+		return nil, nil
+	}
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// CommitPaths creates a new commit containing only the staged changes of the given files.
+// The staged changes of all other files are kept out of the commit and remain staged afterwards.
+//
+// Arguments:
+// - repoPath: The path to the Git repository where the commit should be created.
+// - message: The commit message.
+// - username, email: The identity used as author and committer.
+// - paths: The slash-separated paths of the files to commit, relative to the repository root.
+//
+// Returns:
+// - An error if the index cannot be updated or the commit cannot be created.
+func CommitPaths(repoPath string, message string, username string, email string, paths []string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	headFiles, err := headBlobs(repo)
+	if err != nil {
+		return err
+	}
+	changes, err := stagedChanges(repo)
+	if err != nil {
+		return err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read Git index: %w", err)
+	}
+
+	selected := make(map[string]bool)
+	for _, path := range paths {
+		selected[path] = true
+	}
+
+	// Reset the other files to their state in HEAD, remembering their staged entries
+	deferred := make(map[string]*index.Entry)
+	for _, change := range changes {
+		if selected[change.Path] {
+			continue
+		}
+		deferred[change.Path] = nil
+		if entry, err := idx.Entry(change.Path); err == nil {
+			staged := *entry
+			deferred[change.Path] = &staged
+		}
+		setIndexEntry(idx, change.Path, headFiles[change.Path])
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write Git index: %w", err)
+	}
+
+	commitErr := Commit(repoPath, message, username, email)
+
+	// Stage the other files again, also if the commit failed
+	for path, staged := range deferred {
+		if staged == nil {
+			_, _ = idx.Remove(path)
+			continue
+		}
+		entry, err := idx.Entry(path)
+		if err != nil {
+			entry = idx.Add(path)
+		}
+		*entry = *staged
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return errors.Join(commitErr, fmt.Errorf("failed to restore staged changes: %w", err))
+	}
+	return commitErr
+}
+
+// setIndexEntry sets the index entry of a path to the given file of HEAD, or removes it if the file is nil.
+func setIndexEntry(idx *index.Index, path string, file *object.File) {
+	if file == nil {
+		_, _ = idx.Remove(path)
+		return
+	}
+	entry, err := idx.Entry(path)
+	if err != nil {
+		entry = idx.Add(path)
+	}
+	// Clear the cached file stats, so git compares the working tree file with the new content
+	*entry = index.Entry{Name: path, Hash: file.Hash, Mode: file.Mode}
+}
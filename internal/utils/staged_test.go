@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCommitPaths(t *testing.T) {
+	staged := map[string]string{"a.txt": "a\n", "README.md": "new readme\n", "old.txt": "", "b.txt": "b\n", "keep.txt": ""}
+
+	tests := []struct {
+		name      string
+		committed map[string]string
+		paths     []string
+		want      map[string]string
+	}{
+		{
+			name:      "addition, modification and deletion",
+			committed: map[string]string{"README.md": "readme\n", "old.txt": "old\n", "keep.txt": "keep\n"},
+			paths:     []string{"a.txt", "README.md", "old.txt"},
+			want:      map[string]string{"a.txt": "a\n", "README.md": "new readme\n", "keep.txt": "keep\n"},
+		},
+		{
+			name:      "other group",
+			committed: map[string]string{"README.md": "readme\n", "old.txt": "old\n", "keep.txt": "keep\n"},
+			paths:     []string{"b.txt", "keep.txt"},
+			want:      map[string]string{"b.txt": "b\n", "README.md": "readme\n", "old.txt": "old\n"},
+		},
+		{
+			name:  "without commits",
+			paths: []string{"b.txt"},
+			want:  map[string]string{"b.txt": "b\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for name, content := range staged {
+				if content != "" || test.committed[name] != "" {
+					files[name] = content
+				}
+			}
+			repoPath := stageFiles(t, test.committed, files)
+			before, err := GetStagedChanges(repoPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := CommitPaths(repoPath, "split", "Test", "test@example.com", test.paths); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := headContents(t, repoPath); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("committed files = %v, want %v", got, test.want)
+			}
+
+			// The other files remain staged with their original content
+			selected := make(map[string]bool)
+			for _, path := range test.paths {
+				selected[path] = true
+			}
+			var want []StagedChange
+			for _, change := range before {
+				if !selected[change.Path] {
+					want = append(want, change)
+				}
+			}
+			after, err := GetStagedChanges(repoPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(after, want) {
+				t.Errorf("staged changes = %v, want %v", after, want)
+			}
+		})
+	}
+}
+
+// headContents returns the content of all files in the tree of HEAD by path.
+func headContents(t *testing.T, repoPath string) map[string]string {
+	t.Helper()
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make(map[string]string)
+	err = tree.Files().ForEach(func(file *object.File) error {
+		content, err := file.Contents()
+		contents[file.Name] = content
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contents
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +16,7 @@ import (
 	"github.com/michaelrampl/commity/internal/config"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -68,26 +71,61 @@ func GetDataDir() (string, error) {
 	return appDataDir, nil
 }
 
-// LoadConfig locates and loads the configuration file.
-// It checks each directory from repoPath up to root for a `.commity.yaml`.
-// If none is found, it loads the global config from the user data dir.
+// LoadDictionary loads the dictionary used by a spell check.
+// If the spell check does not name a dictionary, `dictionary.txt` in the data directory is used if it exists.
+//
+// Arguments:
+// - spellCheck: The spell check configuration of a text entry.
+//
+// Returns:
+// - The dictionary, or nil if no dictionary is configured or present, in which case only common misspellings are detected.
+// - An error if the configured dictionary cannot be read.
+func LoadDictionary(spellCheck *config.SpellCheck) (config.Dictionary, error) {
+	path := spellCheck.Dictionary
+	if path == "" || !filepath.IsAbs(path) {
+		dataDir, err := GetDataDir()
+		if err != nil {
+			return nil, err
+		}
+		if path == "" {
+			path = filepath.Join(dataDir, "dictionary.txt")
+			if _, err := os.Stat(path); err != nil {
+				return nil, nil
+			}
+		} else {
+			path = filepath.Join(dataDir, path)
+		}
+	}
+
+	dict, err := config.LoadDictionary(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dictionary: %w", err)
+	}
+	return dict, nil
+}
+
+// FindConfigFile locates the configuration file.
+// It checks each directory from repoPath up to root for a `.commity.yaml`, `.commity.json` or `.commity.toml`
+// as well as a commity section in a `package.json` or `pyproject.toml`.
+// If none is found, it falls back to the global config (`commity.yaml`, `.json` or `.toml`) in the user data dir.
 //
 // Arguments:
 // - repoPath: The starting directory to search for the repo-specific config.
 //
 // Returns:
-// - cfg:       The Configuration loaded from the found file.
-// - configPath: The full path to the config file that was loaded.
-// - error:     If no config file is found or parsing fails.
-func LoadConfig(repoPath string) (*config.Configuration, string, error) {
+// - The full path to the config file.
+// - An error if no config file is found or if multiple config files exist in the same directory.
+func FindConfigFile(repoPath string) (string, error) {
 	// 1) Try walking up from repoPath
 	dir := repoPath
 	for {
-		candidate := filepath.Join(dir, ".commity.yaml")
-		if _, err := os.Stat(candidate); err == nil {
+		candidates := config.FindInDirectory(dir, ".commity", true)
+		if len(candidates) > 1 {
+			return "", fmt.Errorf("multiple config files found in %s: %s", dir, strings.Join(candidates, ", "))
+		}
+		if len(candidates) == 1 {
 			// found a repo-local config
-			cfg, err := config.ParseConfigFile(candidate)
-			return cfg, candidate, err
+			return candidates[0], nil
 		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
@@ -99,22 +137,44 @@ func LoadConfig(repoPath string) (*config.Configuration, string, error) {
 	// 2) Fallback to global config in your data dir
 	dataDir, err := GetDataDir()
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
-	globalConfig := filepath.Join(dataDir, "commity.yaml")
-	if _, err := os.Stat(globalConfig); err == nil {
-		cfg, err := config.ParseConfigFile(globalConfig)
-		return cfg, globalConfig, err
+	candidates := config.FindInDirectory(dataDir, "commity", false)
+	if len(candidates) > 1 {
+		return "", fmt.Errorf("multiple config files found in %s: %s", dataDir, strings.Join(candidates, ", "))
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
 	}
 
-	return nil, "", fmt.Errorf(
+	return "", fmt.Errorf(
 		"no config file found in any parent of %s or in %s",
-		repoPath, globalConfig,
+		repoPath, dataDir,
 	)
 }
 
+// LoadConfig locates and loads the configuration file.
+// See FindConfigFile for the locations that are searched.
+//
+// Arguments:
+// - repoPath: The starting directory to search for the repo-specific config.
+//
+// Returns:
+// - cfg:       The Configuration loaded from the found file.
+// - configPath: The full path to the config file that was loaded.
+// - error:     If no config file is found or parsing fails.
+func LoadConfig(repoPath string) (*config.Configuration, string, error) {
+	configPath, err := FindConfigFile(repoPath)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := config.ParseConfigFile(configPath)
+	return cfg, configPath, err
+}
+
 // RenderCommitMessage generates a commit message using the template string in the configuration.
-// It populates the template with field names and their corresponding values from the configuration entries.
+// It populates the template with field names and their corresponding values from the configuration entries,
+// after evaluating the computed entries.
 //
 // Arguments:
 // - config: A pointer to the Configuration struct containing the template and entries.
@@ -126,12 +186,13 @@ func RenderCommitMessage(config *config.Configuration) (string, error) {
 		return "", fmt.Errorf("template string is empty")
 	}
 
-	// Prepare a map holding the data for the template
-	vars := make(map[string]interface{})
-	for _, entry := range config.Entries {
-		vars[entry.GetName()] = entry.GetValue()
+	if err := EvaluateComputedEntries(config); err != nil {
+		return "", err
 	}
 
+	// Prepare a map holding the data for the template
+	vars := templateData(config)
+
 	// Parse the template string and execute the template engine
 	tmpl, err := template.New("message").Parse(config.Template)
 	if err != nil {
@@ -146,6 +207,80 @@ func RenderCommitMessage(config *config.Configuration) (string, error) {
 	return buf.String(), nil
 }
 
+// EvaluateComputedEntries computes the values of all computed entries from the current values of the other entries.
+// Computed entries are evaluated in dependency order, so they can reference each other.
+//
+// Arguments:
+// - config: A pointer to the Configuration struct containing the entries.
+//
+// Returns:
+// - An error if the computed entries reference each other in a cycle or a template cannot be evaluated.
+func EvaluateComputedEntries(config *config.Configuration) error {
+	order, err := config.ComputedOrder()
+	if err != nil {
+		return err
+	}
+
+	vars := templateData(config)
+	for _, entry := range order {
+		tmpl, err := template.New(entry.Name).Parse(entry.Template)
+		if err != nil {
+			return fmt.Errorf("failed to parse template of computed entry %q: %w", entry.Name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return fmt.Errorf("failed to compute entry %q: %w", entry.Name, err)
+		}
+		entry.Value = buf.String()
+		vars[entry.Name] = entry.Value
+	}
+
+	return nil
+}
+
+// templateData returns the values of all entries by name, as passed to templates and rule expressions.
+func templateData(config *config.Configuration) map[string]interface{} {
+	vars := make(map[string]interface{})
+	for _, entry := range config.Entries {
+		vars[entry.GetName()] = entry.GetValue()
+	}
+	return vars
+}
+
+// RuleViolation describes a rule that is not satisfied by the current entry values.
+type RuleViolation struct {
+	Field   string // The entry the violation is reported for, empty if the rule does not name one
+	Message string // The message of the rule
+}
+
+// CheckRules evaluates the rules of the configuration against the current entry values.
+// Computed entries are evaluated first, so rules can refer to them.
+//
+// Arguments:
+// - config: A pointer to the Configuration struct containing the rules and entries.
+//
+// Returns:
+// - The violations of all rules that are not satisfied, in the order of the rules.
+// - An error if a rule cannot be evaluated.
+func CheckRules(config *config.Configuration) ([]RuleViolation, error) {
+	if err := EvaluateComputedEntries(config); err != nil {
+		return nil, err
+	}
+
+	vars := templateData(config)
+	var violations []RuleViolation
+	for _, rule := range config.Rules {
+		ok, err := rule.Check(vars)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			violations = append(violations, RuleViolation{Field: rule.Field, Message: rule.Message})
+		}
+	}
+	return violations, nil
+}
+
 // Commit creates a new commit in the specified Git repository.
 //
 // Arguments:
@@ -224,6 +359,264 @@ func GetStagedFiles(repoPath string) (int, error) {
 	return stagedFiles, nil
 }
 
+// GetCurrentBranch returns the short name of the branch checked out in the specified Git repository.
+// For a branch without commits the name HEAD points to is returned, for a detached HEAD an empty string.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+//
+// Returns:
+// - The name of the current branch (e.g. "release/1.2").
+// - An error if the repository or its HEAD cannot be read.
+func GetCurrentBranch(repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open Git repository: %w", err)
+	}
+
+	// Read HEAD without resolving it, so branches without commits are supported as well
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		return "", nil
+	}
+	return head.Target().Short(), nil
+}
+
+// GetMainWorktree returns the main worktree of the repository a worktree belongs to.
+// Linked worktrees (see `git worktree`) have a `.git` file pointing to a directory within the `.git` directory
+// of the main worktree, for all other repositories the path is returned unchanged.
+//
+// Arguments:
+// - repoPath: The path to the worktree.
+//
+// Returns:
+// - The path of the main worktree, or of the Git directory for worktrees of a bare repository.
+// - An error if the `.git` file cannot be read.
+func GetMainWorktree(repoPath string) (string, error) {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil || info.IsDir() {
+		return repoPath, nil
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dotGit, err)
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("invalid %s", dotGit)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repoPath, gitDir)
+	}
+
+	// Submodules point to their own Git directory, which has no commondir file
+	commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return repoPath, nil
+	}
+	mainGitDir := strings.TrimSpace(string(commonDir))
+	if !filepath.IsAbs(mainGitDir) {
+		mainGitDir = filepath.Join(gitDir, mainGitDir)
+	}
+	mainGitDir = filepath.Clean(mainGitDir)
+	if filepath.Base(mainGitDir) == ".git" {
+		return filepath.Dir(mainGitDir), nil
+	}
+	return mainGitDir, nil
+}
+
+// GetBranches returns the short names of all local branches of the specified Git repository.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+//
+// Returns:
+// - The names of the branches.
+// - An error if the repository or its references cannot be read.
+func GetBranches(repoPath string) ([]string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branches: %w", err)
+	}
+	var branches []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branches: %w", err)
+	}
+	return branches, nil
+}
+
+// GetRemoteIdentity returns the URL of the origin remote of the specified Git repository in a normalized form,
+// without scheme, user, port and ".git" suffix, so clones over HTTPS and SSH yield the same identity.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+//
+// Returns:
+// - The normalized URL, e.g. "github.com/michaelrampl/commity".
+// - An error if the repository has no origin remote.
+func GetRemoteIdentity(repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read remote %s: %w", git.DefaultRemoteName, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", git.DefaultRemoteName)
+	}
+	return normalizeRemoteURL(urls[0]), nil
+}
+
+// normalizeRemoteURL strips the parts of a remote URL that differ between clones of the same repository.
+func normalizeRemoteURL(remoteURL string) string {
+	host, path := "", strings.TrimSpace(remoteURL)
+	// Only URLs contain "://", "host:path" is the scp-like syntax and "C:/path" a Windows path
+	if parsed, err := url.Parse(path); err == nil && strings.Contains(path, "://") {
+		host, path = parsed.Hostname(), parsed.Path
+	} else if before, after, ok := strings.Cut(path, ":"); ok && len(before) > 1 && !strings.ContainsAny(before, `/\`) {
+		// scp-like syntax, e.g. git@github.com:michaelrampl/commity.git
+		host, path = before, after
+		if _, hostname, ok := strings.Cut(host, "@"); ok {
+			host = hostname
+		}
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" {
+		return path
+	}
+	return strings.ToLower(host) + "/" + path
+}
+
+// GetRootCommit returns the hash of the root commit of the specified Git repository,
+// found by following the first parents from HEAD.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+//
+// Returns:
+// - The hash of the root commit.
+// - An error if the repository has no commits.
+func GetRootCommit(repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open Git repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", head.Hash(), err)
+	}
+	for commit.NumParents() > 0 {
+		if commit, err = commit.Parent(0); err != nil {
+			return "", fmt.Errorf("failed to read parent commit: %w", err)
+		}
+	}
+	return commit.Hash.String(), nil
+}
+
+// IsRootCommit reports whether the hash names a commit without parents in the specified Git repository.
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - hash: The hash of the commit.
+//
+// Returns:
+// - True if the commit exists and has no parents.
+func IsRootCommit(repoPath string, hash string) bool {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return false
+	}
+	return commit.NumParents() == 0
+}
+
+// FixupKinds lists the kinds of commits that are folded into an earlier commit by `git rebase --autosquash`.
+var FixupKinds = []string{"fixup", "squash", "amend"}
+
+// FixupMessage builds the message of a commit that `git rebase --autosquash` folds into the target commit.
+//
+// Arguments:
+// - kind: One of FixupKinds.
+// - target: The commit the new commit is folded into.
+// - message: The message of a squash commit, appended to the target message, or the replacement message of an amend commit.
+//
+// Returns:
+// - The commit message, e.g. "fixup! Add feature".
+func FixupMessage(kind string, target *object.Commit, message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(target.Message), "\n")
+	result := kind + "! " + subject + "\n"
+	if message = strings.TrimSpace(message); message != "" {
+		result += "\n" + message + "\n"
+	}
+	return result
+}
+
+// GetRecentCommits returns the most recent commits reachable from HEAD, ordered by committer time (newest first).
+//
+// Arguments:
+// - repoPath: The path to the Git repository.
+// - limit: The maximum number of commits to return.
+//
+// Returns:
+// - The commits, or an empty list if the repository has no commits yet.
+// - An error if the repository or its history cannot be read.
+func GetRecentCommits(repoPath string, limit int) ([]*object.Commit, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Git repository: %w", err)
+	}
+
+	if _, err := repo.Head(); err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, nil // no commits yet
+		}
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Git log: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []*object.Commit
+	for len(commits) < limit {
+		commit, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read Git log: %w", err)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
 // GetGitIdentity retrieves the Git identity (user.name and user.email)
 // by asking the real `git` binary, which will honor includeIf and all other
 // config magic. It returns an error if either value is empty.